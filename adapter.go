@@ -0,0 +1,176 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/hashicorp/go-argmapper/internal/graph"
+)
+
+// PointerAdapters enables or disables the implicit converters between a
+// type T and *T. These are enabled by default.
+//
+// When enabled, a value of type *T can satisfy an argument of type T by
+// dereferencing it, and a value of type T can satisfy an argument of type
+// *T by taking the address of a copy of it. Dereferencing a nil pointer
+// fails the call with an *ErrConverterFailed error. Any other path to an
+// argument is preferred over these adapters, and a converter with the same
+// signature replaces the adapter.
+func PointerAdapters(enabled bool) Arg {
+	return func(a *argBuilder) error {
+		a.noPointerAdapters = !enabled
+		return nil
+	}
+}
+
+// addPointerAdapters adds the pointer adapters to g for every required type
+// whose pointer or element type is provided by a vertex in g.
+func addPointerAdapters(g *graph.Graph, root graph.Vertex) {
+	requires := map[reflect.Type]struct{}{}
+	provides := map[reflect.Type]struct{}{}
+	for _, raw := range g.Vertices() {
+		switch v := raw.(type) {
+		case *valueVertex:
+			requires[v.Type] = struct{}{}
+			provides[v.Type] = struct{}{}
+
+		case *typedArgVertex:
+			requires[v.Type] = struct{}{}
+
+		case *typedOutputVertex:
+			provides[v.Type] = struct{}{}
+		}
+	}
+
+	for t := range requires {
+		from, ok := pointerCounterpart(t)
+		if !ok {
+			continue
+		}
+		if _, ok := provides[from]; !ok {
+			continue
+		}
+
+		// If a converter with the same signature exists, it is used
+		// instead of our adapter.
+		adapter := pointerAdapter(from, t)
+		if g.Vertex(graph.VertexID(&funcVertex{Func: adapter})) != nil {
+			continue
+		}
+
+		// The edges to the adapter are weighed once the graph is
+		// complete, see weighPointerAdapters.
+		adapter.graph(g, root, true)
+	}
+}
+
+// weighPointerAdapters sets the weight of the edges from the outputs of
+// every pointer adapter in g to the adapter. Adapters are only used as a
+// last resort, so the weight is more than the total weight of every other
+// edge. This makes any path without an adapter shorter than any path with
+// one. This must be called once every edge of g is added.
+func weighPointerAdapters(g *graph.Graph) {
+	var adapters []graph.Vertex
+	for _, raw := range g.Vertices() {
+		if v, ok := raw.(*funcVertex); ok && v.Func.adapter {
+			adapters = append(adapters, v)
+		}
+	}
+	if len(adapters) == 0 {
+		return
+	}
+
+	weight := 1
+	for _, v := range g.Vertices() {
+		if v, ok := v.(*funcVertex); ok && v.Func.adapter {
+			continue
+		}
+
+		for _, out := range g.OutEdges(v) {
+			if out, ok := out.(*funcVertex); ok && out.Func.adapter {
+				continue
+			}
+
+			if w, _ := g.EdgeWeight(v, out); w > 0 {
+				weight += w
+			}
+		}
+	}
+
+	for _, v := range adapters {
+		for _, out := range g.InEdges(v) {
+			g.AddEdgeWeighted(out, v, weight)
+		}
+	}
+}
+
+// pointerCounterpart returns *T for T, or T for *T. This returns false
+// if there is no pointer adapter for t.
+func pointerCounterpart(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() == reflect.Ptr {
+		elem := t.Elem()
+		return elem, pointerAdaptable(elem)
+	}
+
+	return reflect.PointerTo(t), pointerAdaptable(t)
+}
+
+// pointerAdaptable returns true if t can be used with pointer adapters.
+// Pointers to pointers and interfaces are not supported, and neither are
+// structs embedding Struct since these would be treated as named values.
+func pointerAdaptable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return false
+
+	default:
+		return !isStruct(t)
+	}
+}
+
+// pointerAdapter returns the converter from the type from to the type to,
+// where one is a pointer to the other. Adapters are cached since they are
+// created for every call that uses them.
+func pointerAdapter(from, to reflect.Type) *Func {
+	if f, ok := pointerAdapterCache.Load(to); ok {
+		return f.(*Func)
+	}
+
+	var fn reflect.Value
+	if from.Kind() == reflect.Ptr {
+		fn = reflect.MakeFunc(
+			reflect.FuncOf([]reflect.Type{from}, []reflect.Type{to, errType}, false),
+			func(args []reflect.Value) []reflect.Value {
+				if args[0].IsNil() {
+					return []reflect.Value{
+						reflect.Zero(to),
+						reflect.ValueOf(fmt.Errorf("can't dereference nil %s", from)),
+					}
+				}
+
+				return []reflect.Value{args[0].Elem(), reflect.Zero(errType)}
+			})
+	} else {
+		fn = reflect.MakeFunc(
+			reflect.FuncOf([]reflect.Type{from}, []reflect.Type{to}, false),
+			func(args []reflect.Value) []reflect.Value {
+				result := reflect.New(from)
+				result.Elem().Set(args[0])
+				return []reflect.Value{result}
+			})
+	}
+
+	f := MustFunc(NewFunc(fn.Interface(),
+		FuncName(fmt.Sprintf("pointer adapter %s -> %s", from, to))))
+	f.adapter = true
+	actual, _ := pointerAdapterCache.LoadOrStore(to, f)
+	return actual.(*Func)
+}
+
+// pointerAdapterCache caches the result of pointerAdapter by the type
+// that the adapter converts to.
+var pointerAdapterCache sync.Map
@@ -0,0 +1,136 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"sort"
+
+	"github.com/hashicorp/go-argmapper/internal/graph"
+)
+
+// Analysis is the result of Analyze.
+type Analysis struct {
+	// Cycles is the list of converter cycles. A converter cycle is a set
+	// of converters that each (directly or through other converters)
+	// require a value that another converter in the set produces, for
+	// example a converter from A to B and another from B to A. Each cycle
+	// is sorted by converter name.
+	//
+	// A cycle can only be used if a value required by one of its
+	// converters is available from outside the cycle, such as from an
+	// input. Otherwise, none of the converters in the cycle can be called.
+	Cycles [][]*Func
+}
+
+// Analyze analyzes the given values and converters for problems without
+// calling anything. This is meant to be used to validate a set of
+// converters, such as when they are registered, rather than on every call.
+//
+// An error is only returned if the args themselves are invalid or if a
+// converter generator fails.
+func Analyze(opts ...Arg) (*Analysis, error) {
+	builder, err := newArgBuilder(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	g, _, _, _, _, err := buildGraph(builder, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result Analysis
+	for _, cycle := range g.Cycles() {
+		if funcs := cycleFuncs(cycle); len(funcs) > 0 {
+			result.Cycles = append(result.Cycles, funcs)
+		}
+	}
+
+	// Sort our cycles so our result is deterministic.
+	sort.Slice(result.Cycles, func(i, j int) bool {
+		return result.Cycles[i][0].Name() < result.Cycles[j][0].Name()
+	})
+
+	return &result, nil
+}
+
+// prunedCycles returns the converter cycles in g that any of the
+// requirements in reqs that are not visited depend on. A cycle is only
+// returned if none of its vertices are visited, meaning the cycle is
+// pruned from the call graph.
+func prunedCycles(
+	g *graph.Graph,
+	reqs []graph.Vertex,
+	visited map[interface{}]struct{},
+) [][]*Func {
+	// Find all the cycles that were pruned. We track the index of the
+	// cycle for each vertex so we can determine the cycles we depend on.
+	cycleIdx := map[interface{}]int{}
+	var cycles [][]*Func
+CYCLES:
+	for _, cycle := range g.Cycles() {
+		for _, v := range cycle {
+			if _, ok := visited[graph.VertexID(v)]; ok {
+				continue CYCLES
+			}
+		}
+
+		funcs := cycleFuncs(cycle)
+		if len(funcs) == 0 {
+			continue
+		}
+
+		for _, v := range cycle {
+			cycleIdx[graph.VertexID(v)] = len(cycles)
+		}
+		cycles = append(cycles, funcs)
+	}
+	if len(cycles) == 0 {
+		return nil
+	}
+
+	// Go through all our requirements and find the cycles they depend on.
+	found := map[int]struct{}{}
+	check := func(v graph.Vertex) {
+		if idx, ok := cycleIdx[graph.VertexID(v)]; ok {
+			found[idx] = struct{}{}
+		}
+	}
+	for _, req := range reqs {
+		if _, ok := visited[graph.VertexID(req)]; ok {
+			continue
+		}
+
+		check(req)
+		_ = g.DFS(req, func(v graph.Vertex, next func() error) error {
+			check(v)
+			return next()
+		})
+	}
+
+	var result [][]*Func
+	for idx, cycle := range cycles {
+		if _, ok := found[idx]; ok {
+			result = append(result, cycle)
+		}
+	}
+
+	return result
+}
+
+// cycleFuncs returns the converters in the given cycle sorted by name.
+func cycleFuncs(cycle []graph.Vertex) []*Func {
+	var result []*Func
+	for _, v := range cycle {
+		if v, ok := v.(*funcVertex); ok {
+			result = append(result, v.Func)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name() < result[j].Name()
+	})
+
+	return result
+}
@@ -0,0 +1,107 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyze(t *testing.T) {
+	atoi := MustFunc(NewFunc(func(v string) (int, error) {
+		return strconv.Atoi(v)
+	}, FuncName("atoi")))
+	itoa := MustFunc(NewFunc(func(v int) string {
+		return strconv.Itoa(v)
+	}, FuncName("itoa")))
+	widen := MustFunc(NewFunc(func(v int) int64 {
+		return int64(v)
+	}, FuncName("widen")))
+	btoi := MustFunc(NewFunc(func(v bool) int8 {
+		return 1
+	}, FuncName("btoi")))
+	itob := MustFunc(NewFunc(func(v int8) bool {
+		return v != 0
+	}, FuncName("itob")))
+
+	cases := []struct {
+		Name   string
+		Args   []Arg
+		Cycles [][]*Func
+	}{
+		{
+			"no converters",
+			[]Arg{Typed(12)},
+			nil,
+		},
+
+		{
+			"no cycles",
+			[]Arg{ConverterFunc(atoi, widen)},
+			nil,
+		},
+
+		{
+			"cycle",
+			[]Arg{ConverterFunc(itoa, widen, atoi)},
+			[][]*Func{{atoi, itoa}},
+		},
+
+		{
+			"cycle reachable from input",
+			[]Arg{Typed("12"), ConverterFunc(itoa, atoi)},
+			[][]*Func{{atoi, itoa}},
+		},
+
+		{
+			"multiple cycles",
+			[]Arg{ConverterFunc(itob, itoa, btoi, atoi)},
+			[][]*Func{{atoi, itoa}, {btoi, itob}},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			require := require.New(t)
+
+			result, err := Analyze(tt.Args...)
+			require.NoError(err)
+			require.Equal(tt.Cycles, result.Cycles)
+		})
+	}
+}
+
+func TestFuncCall_unsatisfiedCycle(t *testing.T) {
+	require := require.New(t)
+
+	atoi := MustFunc(NewFunc(func(v string) (int, error) {
+		return strconv.Atoi(v)
+	}, FuncName("atoi")))
+	itoa := MustFunc(NewFunc(func(v int) string {
+		return strconv.Itoa(v)
+	}, FuncName("itoa")))
+
+	f, err := NewFunc(func(v int) int { return v })
+	require.NoError(err)
+
+	result := f.Call(ConverterFunc(atoi, itoa))
+	require.Error(result.Err())
+
+	var unsatisfied *ErrArgumentUnsatisfied
+	require.True(errors.As(result.Err(), &unsatisfied))
+	require.Equal([][]*Func{{atoi, itoa}}, unsatisfied.Cycles)
+	require.Contains(unsatisfied.Error(), "Converter cycles")
+
+	// Unrelated cycles are not included
+	result = f.Call(
+		ConverterFunc(MustFunc(NewFunc(func(v bool) int8 { return 1 })),
+			MustFunc(NewFunc(func(v int8) bool { return true }))),
+	)
+	require.True(errors.As(result.Err(), &unsatisfied))
+	require.Empty(unsatisfied.Cycles)
+	require.NotContains(unsatisfied.Error(), "Converter cycles")
+}
@@ -4,7 +4,9 @@
 package argmapper
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"reflect"
 	"strings"
 
@@ -31,8 +33,29 @@ type argBuilder struct {
 	filterInput  FilterFunc
 	filterOutput FilterFunc
 
-	funcName string
-	funcOnce bool
+	funcName    string
+	funcOnce    bool
+	funcMemoize MemoizeKeyFunc
+
+	// ctx is the context for the call, if any. This is set by CallContext.
+	ctx context.Context
+
+	// parallel is the maximum number of goroutines to use for a call.
+	parallel int
+
+	// noPointerAdapters disables the adapters between T and *T. See
+	// PointerAdapters.
+	noPointerAdapters bool
+
+	// interceptors wrap every function invocation. See Interceptor.
+	interceptors []InterceptorFunc
+
+	// tracer traces the steps of a call. See Tracer.
+	tracer SpanTracer
+
+	// recoverPanics turns panics in called functions into errors. See
+	// RecoverPanics.
+	recoverPanics bool
 }
 
 func newArgBuilder(opts ...Arg) (*argBuilder, error) {
@@ -143,6 +166,95 @@ func TypedSubtype(v interface{}, st string) Arg {
 	}
 }
 
+// TypedAs is the same as Typed but the value is registered as type t
+// rather than the type of v. v must be assignable to t. This is typically
+// used to register a value as an interface type it implements, so that it
+// satisfies requirements for that interface exactly. Implicitly matching an
+// interface to an implementation is only used if there is no exact match.
+//
+// If v is nil, the value is the zero value of t.
+func TypedAs(v interface{}, t reflect.Type) Arg {
+	return func(a *argBuilder) error {
+		rv, err := valueAs(v, t)
+		if err != nil {
+			return err
+		}
+
+		a.typed[t] = rv
+		return nil
+	}
+}
+
+// TypedSubtypeAs is the same as TypedSubtype but the value is registered as
+// type t. See TypedAs. If the subtype is empty, this is equivalent to
+// calling TypedAs.
+func TypedSubtypeAs(v interface{}, t reflect.Type, st string) Arg {
+	if st == "" {
+		return TypedAs(v, t)
+	}
+
+	return func(a *argBuilder) error {
+		rv, err := valueAs(v, t)
+		if err != nil {
+			return err
+		}
+
+		if a.typedSub[t] == nil {
+			a.typedSub[t] = map[string]reflect.Value{}
+		}
+		a.typedSub[t][st] = rv
+		return nil
+	}
+}
+
+// NamedAs is the same as Named but the value is registered as type t.
+// See TypedAs. If the name is an empty string, this is equivalent to
+// calling TypedAs.
+func NamedAs(n string, v interface{}, t reflect.Type) Arg {
+	if n == "" {
+		return TypedAs(v, t)
+	}
+
+	return func(a *argBuilder) error {
+		rv, err := valueAs(v, t)
+		if err != nil {
+			return err
+		}
+
+		a.named[strings.ToLower(n)] = rv
+		return nil
+	}
+}
+
+// valueAs returns v as a value of type t. The Type of the result is t even
+// if t is an interface type.
+func valueAs(v interface{}, t reflect.Type) (reflect.Value, error) {
+	if t == nil {
+		return reflect.Value{}, errors.New("type cannot be nil")
+	}
+
+	result := reflect.New(t).Elem()
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		switch t.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice,
+			reflect.Func, reflect.Chan:
+			return result, nil
+
+		default:
+			return reflect.Value{}, fmt.Errorf("nil is not a valid value of type %s", t)
+		}
+	}
+
+	if !rv.Type().AssignableTo(t) {
+		return reflect.Value{}, fmt.Errorf(
+			"value of type %s is not assignable to %s", rv.Type(), t)
+	}
+
+	result.Set(rv)
+	return result, nil
+}
+
 // Converter specifies one or more converters to use if necessary.
 // A converter will be used if an argument type doesn't match exactly.
 func Converter(fs ...interface{}) Arg {
@@ -184,7 +296,8 @@ type ConverterGenFunc func(Value) (*Func, error)
 // used to generate type conversions for example. The returned func can
 // have more requirements.
 //
-// If the function returns a nil Func, then no converter is generated.
+// If the function returns a nil Func, then no converter is generated. If
+// the function returns an error, the call fails with that error.
 func ConverterGen(fs ...ConverterGenFunc) Arg {
 	return func(a *argBuilder) error {
 		a.convGens = append(a.convGens, fs...)
@@ -221,6 +334,45 @@ func Logger(l hclog.Logger) Arg {
 	}
 }
 
+// Parallel allows a call to resolve independent arguments in parallel
+// using up to maxWorkers goroutines, including the calling goroutine.
+//
+// When a function requires multiple arguments that are each reached
+// through different converters, those converter chains are independent
+// and can be called at the same time. This is useful when converters
+// perform slow operations such as I/O. Each converter is still called at
+// most once per call, even if multiple arguments depend on it.
+//
+// Converters used with Parallel must be safe to call concurrently with the
+// other converters in the call.
+//
+// maxWorkers must be at least 1. A value of 1 is equivalent to not
+// specifying Parallel.
+func Parallel(maxWorkers int) Arg {
+	return func(a *argBuilder) error {
+		if maxWorkers < 1 {
+			return fmt.Errorf("parallel maxWorkers must be at least 1, got %d", maxWorkers)
+		}
+
+		a.parallel = maxWorkers
+		return nil
+	}
+}
+
+// RecoverPanics recovers from panics in the converters and the target
+// function of a call. A panic is returned as an error of type *ErrPanic
+// rather than crashing the program. If the panicking function was a
+// converter, the *ErrPanic is wrapped in an *ErrConverterFailed, so use
+// errors.As to check for it.
+//
+// By default, panics are not recovered.
+func RecoverPanics() Arg {
+	return func(a *argBuilder) error {
+		a.recoverPanics = true
+		return nil
+	}
+}
+
 // FuncName sets the function name. This is used only with NewFunc.
 func FuncName(n string) Arg {
 	return func(a *argBuilder) error {
@@ -241,6 +393,11 @@ func FuncName(n string) Arg {
 // will still not be called again. Users of this should be ABSOLUTELY SURE
 // that they want this function to run exactly once regardless of arguments
 // and return the same result every time.
+//
+// This is safe for concurrent calls. If the function is called concurrently
+// before a result is memoized, only one call will run the function and
+// the others will wait for its result. The result is memoized even if
+// it is an error. Use Func.ResetCache to clear the memoized result.
 func FuncOnce() Arg {
 	return func(a *argBuilder) error {
 		a.funcOnce = true
@@ -248,57 +405,74 @@ func FuncOnce() Arg {
 	}
 }
 
-func (b *argBuilder) graph(log hclog.Logger, g *graph.Graph, root graph.Vertex) (
-	[]graph.Vertex, // input vertices
-	[]*Func, // converters
-) {
-	var result []graph.Vertex
-
-	// Add our named inputs
-	for k, v := range b.named {
-		// Add the input
-		input := g.AddOverwrite(&valueVertex{
-			Name:  k,
-			Type:  v.Type(),
-			Value: v,
-		})
-		log.Trace("input", "kind", "named", "name", k, "type", v.Type(), "value", v)
+// MemoizeKeyFunc returns the key used to memoize a function result for the
+// given input values. See FuncMemoize.
+type MemoizeKeyFunc func(*ValueSet) interface{}
 
-		// Input depends on the input root
-		g.AddEdge(input, root)
+// FuncMemoize configures the function to memoize its results based on its
+// input values. This is used only with NewFunc.
+//
+// The key function is called with the input values for every call and
+// must return a comparable value (usable as a map key). The function is
+// called at most once per distinct key and the result is returned for
+// every call with the same key. Like FuncOnce, this is safe for concurrent
+// calls and results are memoized even if they are an error.
+//
+// Memoized results are kept until Func.ResetCache is called.
+func FuncMemoize(key MemoizeKeyFunc) Arg {
+	return func(a *argBuilder) error {
+		if key == nil {
+			return errors.New("memoize key function cannot be nil")
+		}
 
-		// Track
-		result = append(result, input)
+		a.funcMemoize = key
+		return nil
 	}
+}
 
-	// Add our named inputs with subtypes
-	for k, m := range b.namedSub {
-		for st, v := range m {
-			// Add the input
-			input := g.AddOverwrite(&valueVertex{
-				Name:    k,
-				Type:    v.Type(),
-				Subtype: st,
-				Value:   v,
-			})
-			log.Trace("input", "kind", "named", "name", k, "value", v, "subtype", st)
-
-			// Input depends on the input root
-			g.AddEdge(input, root)
+// withContextArgs returns a copy of opts with withContext(ctx) appended.
+// This is used by CallContext and friends so that opts isn't modified.
+func withContextArgs(ctx context.Context, opts []Arg) []Arg {
+	result := make([]Arg, len(opts), len(opts)+1)
+	copy(result, opts)
+	return append(result, withContext(ctx))
+}
 
-			// Track
-			result = append(result, input)
+// withContext sets the context for the call. The context is also made
+// available as a typed value of type context.Context. This is used by
+// CallContext and friends.
+func withContext(ctx context.Context) Arg {
+	return func(a *argBuilder) error {
+		if ctx == nil {
+			return errors.New("context cannot be nil")
 		}
+
+		a.ctx = ctx
+
+		// We register the value with the context.Context type rather
+		// than the concrete type so that it is an exact match for any
+		// context.Context argument.
+		a.typed[contextType] = reflect.ValueOf(&ctx).Elem()
+		return nil
 	}
+}
 
-	// Add our typed inputs
-	for t, v := range b.typed {
+// contextVertexID is the ID of the input vertex of the context given
+// with withContext.
+var contextVertexID = graph.VertexID(&typedOutputVertex{Type: contextType})
+
+func (b *argBuilder) graph(log hclog.Logger, g *graph.Graph, root graph.Vertex) (
+	[]graph.Vertex, // input vertices
+	[]*Func, // converters
+	error,
+) {
+	var result []graph.Vertex
+
+	// Add our inputs
+	for _, v := range b.inputs() {
 		// Add the input
-		input := g.AddOverwrite(&typedOutputVertex{
-			Type:  t,
-			Value: v,
-		})
-		log.Trace("input", "kind", "typed", "type", t, "value", v)
+		input := g.AddOverwrite(v)
+		log.Trace("input", "value", newValueFromVertex(v))
 
 		// Input depends on the input root
 		g.AddEdge(input, root)
@@ -307,25 +481,6 @@ func (b *argBuilder) graph(log hclog.Logger, g *graph.Graph, root graph.Vertex)
 		result = append(result, input)
 	}
 
-	// Add our typed inputs with subtypes
-	for t, m := range b.typedSub {
-		for st, v := range m {
-			// Add the input
-			input := g.AddOverwrite(&typedOutputVertex{
-				Type:    t,
-				Value:   v,
-				Subtype: st,
-			})
-			log.Trace("input", "kind", "typed", "type", t, "value", v, "subtype", st)
-
-			// Input depends on the input root
-			g.AddEdge(input, root)
-
-			// Track
-			result = append(result, input)
-		}
-	}
-
 	// If we have converters, add those.
 	for _, f := range b.convs {
 		f.graph(g, root, true)
@@ -347,8 +502,8 @@ func (b *argBuilder) graph(log hclog.Logger, g *graph.Graph, root graph.Vertex)
 			for _, gen := range b.convGens {
 				f, err := gen(*value)
 				if err != nil {
-					// TODO: return
-					panic(err)
+					return nil, nil, fmt.Errorf(
+						"converter generator failed for %s: %w", value.String(), err)
 				}
 				if f == nil {
 					continue
@@ -360,5 +515,97 @@ func (b *argBuilder) graph(log hclog.Logger, g *graph.Graph, root graph.Vertex)
 		}
 	}
 
-	return result, convs
+	return result, convs, nil
+}
+
+// merge merges the values and converters of other into this builder.
+// Values in other replace any values in this builder with the same name,
+// type, and subtype. Converters in other replace any converters in this
+// builder with the same function type, since only one converter of a given
+// type can be used for a call. Any other settings of other are ignored.
+func (b *argBuilder) merge(other *argBuilder) {
+	for k, v := range other.named {
+		b.named[k] = v
+	}
+	for k, m := range other.namedSub {
+		if b.namedSub[k] == nil {
+			b.namedSub[k] = map[string]reflect.Value{}
+		}
+		for st, v := range m {
+			b.namedSub[k][st] = v
+		}
+	}
+	for t, v := range other.typed {
+		b.typed[t] = v
+	}
+	for t, m := range other.typedSub {
+		if b.typedSub[t] == nil {
+			b.typedSub[t] = map[string]reflect.Value{}
+		}
+		for st, v := range m {
+			b.typedSub[t][st] = v
+		}
+	}
+
+CONVS:
+	for _, f := range other.convs {
+		for i, existing := range b.convs {
+			if existing.fn.Type() == f.fn.Type() {
+				b.convs[i] = f
+				continue CONVS
+			}
+		}
+
+		b.convs = append(b.convs, f)
+	}
+
+	b.convGens = append(b.convGens, other.convGens...)
+}
+
+// inputs returns the vertices for all the direct values (named, typed, etc.)
+// set on this builder. The vertices have their Value fields set.
+func (b *argBuilder) inputs() []graph.Vertex {
+	var result []graph.Vertex
+
+	// Named inputs
+	for k, v := range b.named {
+		result = append(result, &valueVertex{
+			Name:  k,
+			Type:  v.Type(),
+			Value: v,
+		})
+	}
+
+	// Named inputs with subtypes
+	for k, m := range b.namedSub {
+		for st, v := range m {
+			result = append(result, &valueVertex{
+				Name:    k,
+				Type:    v.Type(),
+				Subtype: st,
+				Value:   v,
+			})
+		}
+	}
+
+	// Typed inputs
+	for t, v := range b.typed {
+		result = append(result, &typedOutputVertex{
+			Type:  t,
+			Value: v,
+		})
+	}
+
+	// Typed inputs with subtypes
+	for t, m := range b.typedSub {
+		for st, v := range m {
+			result = append(result, &typedOutputVertex{
+				Type:    t,
+				Value:   v,
+				Subtype: st,
+			})
+		}
+	}
+
+	return result
 }
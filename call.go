@@ -4,8 +4,14 @@
 package argmapper
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"sync/atomic"
 
 	"github.com/hashicorp/go-argmapper/internal/graph"
 	"github.com/hashicorp/go-hclog"
@@ -24,20 +30,57 @@ func (f *Func) Call(opts ...Arg) Result {
 	log := builder.logger
 	log.Trace("call")
 
+	traceCtx, span := startSpan(builder.tracer, builder.ctx, SpanCall, "func", f.Name())
+	result := f.callTraced(log, builder, traceCtx)
+	span.End(result.Err())
+	return result
+}
+
+// callTraced is the implementation of Call. traceCtx is the context of
+// the SpanCall span of the call.
+func (f *Func) callTraced(log hclog.Logger, builder *argBuilder, traceCtx context.Context) (result Result) {
 	// Build our call graph
+	_, graphSpan := startSpan(builder.tracer, traceCtx, SpanGraph, "func", f.Name())
 	g, vertexRoot, vertexF, _, err := f.callGraph(builder)
+	graphSpan.End(err, "vertices", len(g.Vertices()))
 	if err != nil {
 		return resultError(err)
 	}
 
 	// Reach our target function to get our arguments, performing any
 	// conversions necessary.
-	argMap, err := f.reachTarget(log, &g, vertexRoot, vertexF, newCallState(), false)
+	state := newCallState()
+	state.Context = builder.ctx
+	state.interceptors = builder.interceptors
+	state.tracer = builder.tracer
+	state.traceCtx = traceCtx
+	state.recoverPanics = builder.recoverPanics
+	state.setWorkers(builder.parallel)
+	defer func() { result = state.finish(log, result) }()
+	argMap, err := f.reachTarget(log, &g, vertexRoot, vertexF, state, false)
 	if err != nil {
 		return resultError(err)
 	}
 
-	return f.callDirect(log, argMap)
+	// Verify we haven't been canceled while reaching our target.
+	if err := state.checkContext(f); err != nil {
+		return resultError(err)
+	}
+
+	return f.callDirect(log, state, argMap, false)
+}
+
+// CallContext is the same as Call but with a context for the call.
+//
+// The context is checked before calling any converter and before calling
+// the target function. If the context is done, the call stops and returns
+// an *ErrCallCanceled error that wraps the context error.
+//
+// The context is also automatically provided to any converter or target
+// function argument of type context.Context, so it doesn't need to be
+// given with Typed.
+func (f *Func) CallContext(ctx context.Context, opts ...Arg) Result {
+	return f.Call(withContextArgs(ctx, opts)...)
 }
 
 // callGraph builds the common graph used by Call, Redefine, etc.
@@ -50,21 +93,171 @@ func (f *Func) callGraph(args *argBuilder) (
 ) {
 	log := args.logger
 
+	// Build the full graph. This may contain cycles and vertices that
+	// are unreachable from our inputs.
+	var convs []*Func
+	g, vertexRoot, vertexF, vertexI, convs, err = buildGraph(args, f)
+	if err != nil {
+		return
+	}
+	vertexFreq := g.OutEdges(vertexF)
+	log.Trace("full graph (may have cycles)", "graph", g.String())
+
+	// Optional requirements can be pruned without failing the call.
+	optional := map[interface{}]struct{}{}
+	for _, val := range f.input.values {
+		if val.optional {
+			optional[graph.VertexID(val.vertex())] = struct{}{}
+		}
+	}
+
+	// Next we do a DFS from each input A in I to the function F.
+	// This gives us the full set of reachable nodes from our inputs
+	// and at most to F. Using this information, we can prune any nodes
+	// that are guaranteed to be unused.
+	//
+	// DFS from the input root and record what we see. We have to reverse the
+	// graph here because we typically have out edges pointing to
+	// requirements, but we're going from requirements (inputs) to
+	// the function.
+	visited := map[interface{}]struct{}{
+		// We must keep the root. Since we're starting from the root we don't
+		// "visit" it. But we must keep it for shortest path calculations. If
+		// we don't keep it, our shortest path calculations are from some
+		// other zero index topo sort value.
+		graph.VertexID(vertexRoot): struct{}{},
+	}
+	_ = g.Reverse().DFS(vertexRoot, func(v graph.Vertex, next func() error) error {
+		visited[graph.VertexID(v)] = struct{}{}
+
+		if v == vertexF {
+			return nil
+		}
+		return next()
+	})
+
+	// If any of our requirements weren't visited, then determine if they
+	// depend on any converter cycles. These cycles are pruned since none of
+	// their converters can be called, and we note them in our error.
+	var cycles [][]*Func
+	for _, req := range vertexFreq {
+		if _, ok := optional[graph.VertexID(req)]; ok {
+			continue
+		}
+
+		if _, ok := visited[graph.VertexID(req)]; !ok {
+			cycles = prunedCycles(&g, vertexFreq, visited)
+			break
+		}
+	}
+
+	// Remove all the non-visited vertices. After this, what we'll have
+	// is a graph that has many paths getting us from inputs to function,
+	// but we will have no spurious vertices that are unreachable from our
+	// inputs.
+	for _, v := range g.Vertices() {
+		if _, ok := visited[graph.VertexID(v)]; !ok {
+			g.Remove(v)
+		}
+	}
+	log.Trace("graph after input DFS", "graph", g.String())
+
+	// Go through all our inputs. If any aren't in the graph any longer
+	// it means there is no possible path to that input so it cannot be
+	// satisfied.
+	var unsatisfied []*Value
+	for _, req := range vertexFreq {
+		if _, ok := optional[graph.VertexID(req)]; ok {
+			continue
+		}
+
+		if g.Vertex(graph.VertexID(req)) == nil {
+			valueable, ok := req.(valueConverter)
+			if !ok {
+				// This shouldn't be possible
+				panic(fmt.Sprintf("argmapper graph node doesn't implement value(): %T", req))
+			}
+
+			unsatisfied = append(unsatisfied, valueable.value())
+		}
+	}
+
+	// If we have unsatisfied inputs, then put together the data we need to
+	// build our error result and return it.
+	if len(unsatisfied) > 0 {
+		// Build our list of direct inputs
+		var inputs []*Value
+		for _, v := range vertexI {
+			valueable, ok := v.(valueConverter)
+			if !ok {
+				// This shouldn't be possible
+				panic(fmt.Sprintf("argmapper graph node doesn't implement value(): %T", v))
+			}
+
+			inputs = append(inputs, valueable.value())
+		}
+
+		err = &ErrArgumentUnsatisfied{
+			Func:       f,
+			Args:       unsatisfied,
+			Inputs:     inputs,
+			Converters: convs,
+			Cycles:     cycles,
+		}
+		return
+	}
+
+	return
+}
+
+// buildGraph builds the full graph for calling target with the given args.
+// The graph may contain cycles and vertices that can't be reached from the
+// inputs, which callGraph prunes. If target is nil, the graph only contains
+// the inputs and converters and vertexF is nil.
+func buildGraph(args *argBuilder, target *Func) (
+	g graph.Graph,
+	vertexRoot graph.Vertex,
+	vertexF graph.Vertex,
+	vertexI []graph.Vertex,
+	convs []*Func,
+	err error,
+) {
+	log := args.logger
+
 	// Create a shared root. Anything reachable from the root is not pruned.
 	// This is primarily inputs but may also contain parameterless converters
 	// (providers).
 	vertexRoot = g.Add(&rootVertex{})
 
 	// Build the graph. The first step is to add our function and all the
-	// requirements of the function. We keep track of this in vertexF and
-	// vertexT, respectively, because we'll need these later.
-	vertexF = f.graph(&g, vertexRoot, false)
-	vertexFreq := g.OutEdges(vertexF)
+	// requirements of the function. We keep track of this in vertexF
+	// because we'll need it later.
+	if target != nil {
+		vertexF = target.graph(&g, vertexRoot, false)
+
+		// When redefining, optional arguments are never required by the
+		// redefined function, so we don't try to reach them.
+		if args.redefining {
+			for _, val := range target.input.values {
+				if val.optional {
+					g.RemoveEdge(vertexF, g.Vertex(graph.VertexID(val.vertex())))
+				}
+			}
+		}
+	}
 
 	// Next, we add "inputs", which are the given named values that
 	// we already know about. These are tracked as "vertexI".
-	var convs []*Func
-	vertexI, convs = args.graph(log, &g, vertexRoot)
+	vertexI, convs, err = args.graph(log, &g, vertexRoot)
+	if err != nil {
+		return
+	}
+
+	// Add the adapters between T and *T, if enabled. These are only
+	// added when we have a target since they aren't user converters.
+	if target != nil && !args.noPointerAdapters {
+		addPointerAdapters(&g, vertexRoot)
+	}
 
 	// Next, for all values we may have or produce, we need to create
 	// the vertices for the type-only value. This lets us say, for example,
@@ -236,82 +429,8 @@ func (f *Func) callGraph(args *argBuilder) (
 		}
 	}
 
-	log.Trace("full graph (may have cycles)", "graph", g.String())
-
-	// Next we do a DFS from each input A in I to the function F.
-	// This gives us the full set of reachable nodes from our inputs
-	// and at most to F. Using this information, we can prune any nodes
-	// that are guaranteed to be unused.
-	//
-	// DFS from the input root and record what we see. We have to reverse the
-	// graph here because we typically have out edges pointing to
-	// requirements, but we're going from requirements (inputs) to
-	// the function.
-	visited := map[interface{}]struct{}{
-		// We must keep the root. Since we're starting from the root we don't
-		// "visit" it. But we must keep it for shortest path calculations. If
-		// we don't keep it, our shortest path calculations are from some
-		// other zero index topo sort value.
-		graph.VertexID(vertexRoot): struct{}{},
-	}
-	_ = g.Reverse().DFS(vertexRoot, func(v graph.Vertex, next func() error) error {
-		visited[graph.VertexID(v)] = struct{}{}
-
-		if v == vertexF {
-			return nil
-		}
-		return next()
-	})
-
-	// Remove all the non-visited vertices. After this, what we'll have
-	// is a graph that has many paths getting us from inputs to function,
-	// but we will have no spurious vertices that are unreachable from our
-	// inputs.
-	for _, v := range g.Vertices() {
-		if _, ok := visited[graph.VertexID(v)]; !ok {
-			g.Remove(v)
-		}
-	}
-	log.Trace("graph after input DFS", "graph", g.String())
-
-	// Go through all our inputs. If any aren't in the graph any longer
-	// it means there is no possible path to that input so it cannot be
-	// satisfied.
-	var unsatisfied []*Value
-	for _, req := range vertexFreq {
-		if g.Vertex(graph.VertexID(req)) == nil {
-			valueable, ok := req.(valueConverter)
-			if !ok {
-				// This shouldn't be possible
-				panic(fmt.Sprintf("argmapper graph node doesn't implement value(): %T", req))
-			}
-
-			unsatisfied = append(unsatisfied, valueable.value())
-		}
-	}
-
-	// If we have unsatisfied inputs, then put together the data we need to
-	// build our error result and return it.
-	if len(unsatisfied) > 0 {
-		// Build our list of direct inputs
-		var inputs []*Value
-		for _, v := range vertexI {
-			valueable, ok := v.(valueConverter)
-			if !ok {
-				// This shouldn't be possible
-				panic(fmt.Sprintf("argmapper graph node doesn't implement value(): %T", v))
-			}
-
-			inputs = append(inputs, valueable.value())
-		}
-
-		err = &ErrArgumentUnsatisfied{
-			Func:       f,
-			Args:       unsatisfied,
-			Inputs:     inputs,
-			Converters: convs,
-		}
-		return
+	if target != nil && !args.noPointerAdapters {
+		weighPointerAdapters(&g)
 	}
 
 	return
@@ -337,9 +456,17 @@ func (f *Func) reachTarget(
 	// already then we skip the target because we assume it is already in
 	// the state.
 	var vertexT []graph.Vertex
+	var vertexC []*collectVertex
+	state.lock.Lock()
 	for _, out := range g.OutEdges(target) {
 		skip := false
 		switch v := out.(type) {
+		case *collectVertex:
+			// Collected values aren't reached through a path, we
+			// collect them below.
+			vertexC = append(vertexC, v)
+			continue
+
 		case *rootVertex:
 			// If we see a root vertex, then that means that this target
 			// has no dependencies.
@@ -362,6 +489,17 @@ func (f *Func) reachTarget(
 		log.Trace("conv is missing an input", "input", out)
 		vertexT = append(vertexT, out)
 	}
+	state.lock.Unlock()
+
+	// Collect any values we need to collect.
+	for _, v := range vertexC {
+		value, err := f.collect(log, g, root, v, state, redefine)
+		if err != nil {
+			return nil, err
+		}
+
+		argMap[graph.VertexID(v)] = value
+	}
 
 	if len(vertexT) == 0 {
 		log.Trace("conv satisfied")
@@ -373,30 +511,18 @@ func (f *Func) reachTarget(
 	// Waypoint usage it happens here.
 	var unsatisfied []*Value
 
+	var targetName string
+	if v, ok := target.(*funcVertex); ok {
+		targetName = v.Func.Name()
+	}
+	_, pathsSpan := startSpan(state.tracer, state.traceCtx, SpanPaths,
+		"func", targetName, "requirements", len(vertexT))
+
 	paths := make([][]graph.Vertex, len(vertexT))
 	for i, current := range vertexT {
-		currentG := g
-
-		// For value vertices, we discount any other values that share the
-		// same name. This lets our shortest paths prefer matching through
-		// same-named arguments.
-		if currentValue, ok := current.(*valueVertex); ok {
-			currentG = currentG.Copy()
-			for _, raw := range currentG.Vertices() {
-				if v, ok := raw.(*valueVertex); ok && v.Name == currentValue.Name {
-					for _, src := range currentG.InEdges(raw) {
-						currentG.AddEdgeWeighted(src, raw, weightMatchingName)
-					}
-				}
-			}
-		}
-
-		// Recalculate the shortest path information since we may changed
-		// the graph above.
-		_, edgeTo := currentG.Reverse().Dijkstra(root)
-
-		// With the latest shortest paths, let's add the path for this target.
-		paths[i] = currentG.EdgeToPath(current, edgeTo)
+		// Get the shortest path to this target. The path is only
+		// calculated once per graph and is cached for subsequent use.
+		paths[i] = state.Paths.path(g, root, current)
 		log.Trace("path for target", "target", current, "path", paths[i])
 
 		// Get the input
@@ -419,7 +545,14 @@ func (f *Func) reachTarget(
 		}
 
 		// Store our input used
+		state.lock.Lock()
 		state.InputSet[graph.VertexID(input)] = input
+		if state.record {
+			state.Walked = append(state.Walked, walkedPath{
+				Target: target,
+				Path:   paths[i],
+			})
+		}
 
 		// When we're redefining, we always set the initial input to
 		// the zero value because we assume we'll have access to it. We
@@ -435,11 +568,12 @@ func (f *Func) reachTarget(
 				v.Value = reflect.Zero(v.Type)
 			}
 		}
+		state.lock.Unlock()
 	}
 
 	// If we have any unsatisfied values, error.
 	if len(unsatisfied) > 0 {
-		return nil, &ErrArgumentUnsatisfied{
+		err := &ErrArgumentUnsatisfied{
 			Func: f,
 			Args: unsatisfied,
 
@@ -447,131 +581,515 @@ func (f *Func) reachTarget(
 			// which is theoretically possible but a lot more difficult.
 			// Given this error is relatively rare, we can tackle this later.
 		}
+		pathsSpan.End(err)
+		return nil, err
+	}
+	pathsSpan.End(nil)
+
+	// Lazy requirements are walked when their value is first needed
+	// rather than now. When recording, we always walk every path so that
+	// the whole call can be explained.
+	lazy := map[interface{}]reflect.Type{}
+	if v, ok := target.(*funcVertex); ok && !state.record {
+		for _, val := range v.Func.input.values {
+			if val.lazyType != nil {
+				lazy[graph.VertexID(val.vertex())] = val.lazyType
+			}
+		}
 	}
 
-	// Go through each path
-	for _, path := range paths {
-		// finalValue will be set to our final value that we see when walking.
-		// This will be set as the value for this required input.
-		var finalValue reflect.Value
+	// Go through each path. If we're allowed to use multiple workers,
+	// then paths are walked in parallel. The last path is always walked by
+	// the current goroutine since it would otherwise be waiting idle.
+	finalValues := make([]reflect.Value, len(paths))
+	errs := make([]error, len(paths))
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		if _, ok := lazy[graph.VertexID(path[len(path)-1])]; ok {
+			continue
+		}
 
-		for pathIdx, vertex := range path {
-			log.Trace("executing node", "current", vertex)
-			switch v := vertex.(type) {
-			case *rootVertex:
-				// Do nothing
+		walk := func() {
+			finalValues[i], errs[i] = f.walkPath(log, g, root, path, state, redefine)
+		}
 
-			case *valueVertex:
-				// Store the last viewed vertex in our path state
-				state.Value = v.Value
-
-				if pathIdx > 0 {
-					prev := path[pathIdx-1]
-					if r, ok := prev.(*typedOutputVertex); ok {
-						log.Trace("setting node value", "value", r.Value)
-						v.Value = r.Value
-					}
-				}
+		if i < len(paths)-1 && state.acquireWorker() {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer state.releaseWorker()
+				walk()
+			}()
+
+			continue
+		}
 
-				// If we have a valid value set, then put it on our named list.
-				if v.Value.IsValid() {
-					state.NamedValue[v.Name] = v.Value
+		walk()
+		if errs[i] != nil {
+			break
+		}
+	}
+	wg.Wait()
 
-					finalValue = v.Value
-				}
+	for i, path := range paths {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
 
-			case *typedArgVertex:
-				// If we have a value set on the state then we set that to this
-				// value. This is true in every Call case but is always false
-				// for Redefine.
-				if state.Value.IsValid() && state.Value.Type().AssignableTo(v.Type) {
-					// The value of this is the last value vertex we saw. The graph
-					// walk should ensure this is the correct type.
-					v.Value = state.Value
+		// Lazy values walk their path when they're resolved.
+		id := graph.VertexID(path[len(path)-1])
+		if lazyType, ok := lazy[id]; ok {
+			state.lazy.Store(true)
+			argMap[id] = newLazy(lazyType, func() (reflect.Value, error) {
+				return f.walkPath(log, g, root, path, state, redefine)
+			})
+
+			continue
+		}
+
+		// We store the final value in the input map.
+		log.Trace("final value", "vertex", path[len(path)-1], "value", finalValues[i].Interface())
+		argMap[graph.VertexID(path[len(path)-1])] = finalValues[i]
+	}
+
+	// Reached our goal
+	return argMap, nil
+}
+
+// walkPath walks a single path from the root to a required value,
+// calling any converters along the way. This returns the final value
+// for the path.
+func (f *Func) walkPath(
+	log hclog.Logger,
+	g *graph.Graph,
+	root graph.Vertex,
+	path []graph.Vertex,
+	state *callState,
+	redefine bool,
+) (reflect.Value, error) {
+	// finalValue will be set to our final value that we see when walking.
+	// This will be set as the value for this required input.
+	var finalValue reflect.Value
+
+	// lastValue is the last seen value. We use this to set the
+	// typedArgVertex values properly.
+	var lastValue reflect.Value
+
+	for pathIdx, vertex := range path {
+		log.Trace("executing node", "current", vertex)
+
+		// Functions are called without holding the state lock so that
+		// other paths can be walked while we wait.
+		if v, ok := vertex.(*funcVertex); ok {
+			if _, err := f.callConverter(log, g, root, v, path[len(path)-1], state, redefine); err != nil {
+				return reflect.Value{}, err
+			}
+
+			continue
+		}
+
+		state.lock.Lock()
+		switch v := vertex.(type) {
+		case *rootVertex:
+			// Do nothing
+
+		case *collectVertex:
+			// Do nothing, the values are collected when reaching the
+			// function that requires them.
+
+		case *valueVertex:
+			// Store the last viewed vertex in our path state
+			lastValue = v.Value
+
+			if pathIdx > 0 {
+				prev := path[pathIdx-1]
+				if r, ok := prev.(*typedOutputVertex); ok {
+					log.Trace("setting node value", "value", r.Value)
+					v.Value = r.Value
 				}
+			}
 
-				// Setup our mapping so that we know that this wildcard
-				// maps to this name.
-				state.TypedValue[v.Type] = v.Value
+			// If we have a valid value set, then put it on our named list.
+			if v.Value.IsValid() {
+				state.NamedValue[v.Name] = v.Value
 
 				finalValue = v.Value
+			}
 
-			case *typedOutputVertex:
-				// If our last node was another typed output, then we take
-				// that value.
-				if pathIdx > 0 {
-					prev := path[pathIdx-1]
-					if r, ok := prev.(*typedOutputVertex); ok {
-						log.Trace("setting node value", "value", r.Value)
-						v.Value = r.Value
-					}
-				}
+		case *typedArgVertex:
+			// If we have a value set on the state then we set that to this
+			// value. This is true in every Call case but is always false
+			// for Redefine.
+			if lastValue.IsValid() && lastValue.Type().AssignableTo(v.Type) {
+				// The value of this is the last value vertex we saw. The graph
+				// walk should ensure this is the correct type.
+				v.Value = lastValue
+			}
 
-				// Last value
-				state.Value = v.Value
-
-				// Set the typed value we can read from.
-				state.TypedValue[v.Type] = v.Value
-
-			case *funcVertex:
-				// Reach our arguments if they aren't already.
-				funcArgMap, err := f.reachTarget(
-					log, //log.Named(graph.VertexName(v)),
-					g,
-					root,
-					v,
-					state,
-					redefine,
-				)
-				if err != nil {
-					return nil, err
-				}
+			// Setup our mapping so that we know that this wildcard
+			// maps to this name.
+			state.TypedValue[v.Type] = v.Value
 
-				// Call our function.
-				result := v.Func.callDirect(log, funcArgMap)
-				if err := result.Err(); err != nil {
-					return nil, err
+			finalValue = v.Value
+
+		case *typedOutputVertex:
+			// If our last node was another typed output, then we take
+			// that value.
+			if pathIdx > 0 {
+				prev := path[pathIdx-1]
+				if r, ok := prev.(*typedOutputVertex); ok {
+					log.Trace("setting node value", "value", r.Value)
+					v.Value = r.Value
 				}
+			}
+
+			// Last value
+			lastValue = v.Value
+
+			// Set the typed value we can read from.
+			state.TypedValue[v.Type] = v.Value
+
+		default:
+			state.lock.Unlock()
+			panic(fmt.Sprintf("unknown vertex: %v", v))
+		}
+		state.lock.Unlock()
+	}
+
+	// We should always have a final value, because our execution to
+	// this point only leads up to this value.
+	if !finalValue.IsValid() {
+		panic(fmt.Sprintf("didn't reach a final value for path: %#v", path))
+	}
+
+	return finalValue, nil
+}
+
+// callConverter reaches the arguments for the converter v and calls it,
+// setting the output values in the graph. Each converter is called at
+// most once per call. If the converter was already called, the result
+// of that call is returned.
+//
+// target is the requirement that the converter is being called to reach.
+// This is used for errors.
+func (f *Func) callConverter(
+	log hclog.Logger,
+	g *graph.Graph,
+	root graph.Vertex,
+	v *funcVertex,
+	target graph.Vertex,
+	state *callState,
+	redefine bool,
+) (Result, error) {
+	result := state.Calls.do(graph.VertexID(v), state.concurrent(), func() Result {
+		// If our context is done, don't call anything else.
+		if err := state.checkContext(v.Func); err != nil {
+			return resultError(err)
+		}
 
-				// Update our graph nodes and continue
-				v.Func.outputValues(result, g.InEdges(v), state)
+		// Reach our arguments if they aren't already.
+		funcArgMap, err := f.reachTarget(
+			log, //log.Named(graph.VertexName(v)),
+			g,
+			root,
+			v,
+			state,
+			redefine,
+		)
+		if err != nil {
+			return resultError(err)
+		}
+
+		// Call our function.
+		result := v.Func.callDirect(log, state, funcArgMap, true)
+
+		state.lock.Lock()
+		defer state.lock.Unlock()
+		if err := result.Err(); err != nil {
+			called := make([]*Func, len(state.Called))
+			copy(called, state.Called)
+
+			value := target.(valueConverter).value()
+			value.Value = reflect.Value{}
+			return resultError(&ErrConverterFailed{
+				Func:   v.Func,
+				Target: *value,
+				Path:   called,
+				Err:    err,
+			})
+		}
+
+		state.Called = append(state.Called, v.Func)
+		return result
+	})
+	if err := result.Err(); err != nil {
+		return result, err
+	}
+
+	// Update our graph nodes
+	state.lock.Lock()
+	defer state.lock.Unlock()
+	v.Func.outputValues(result, g.InEdges(v), state)
+	return result, nil
+}
+
+// collect builds the slice value for the collect vertex v. The slice
+// contains every direct input assignable to the element type of the slice,
+// sorted by their name, type, and subtype, followed by the outputs of every
+// converter that produces a value assignable to the element type, sorted
+// by converter name and then type. Values that are themselves slices
+// assignable to the slice type are flattened into the result.
+//
+// If v is a named map, the map instead contains every named direct input
+// and named converter output assignable to the element type of the map,
+// keyed by name. Converter outputs replace direct inputs of the same name.
+//
+// The context.Context input of the call is only collected if the element
+// type is context.Context. Converters that can't be called with the
+// available inputs are skipped.
+// Converters that depend on any collected value are also skipped, since
+// they may depend on this collection, as are pointer adapters. When
+// redefining, only direct inputs are collected.
+func (f *Func) collect(
+	log hclog.Logger,
+	g *graph.Graph,
+	root graph.Vertex,
+	v *collectVertex,
+	state *callState,
+	redefine bool,
+) (reflect.Value, error) {
+	log.Trace("collecting values", "type", v.Type)
+
+	var result reflect.Value
+	if v.Named {
+		result = reflect.MakeMap(v.Type)
+	} else {
+		result = reflect.MakeSlice(v.Type, 0, 0)
+	}
+	add := func(name string, val reflect.Value) bool {
+		switch {
+		case !v.accepts(name, val.Type()):
+			return false
 
-			default:
-				panic(fmt.Sprintf("unknown vertex: %v", v))
+		case v.Named:
+			key := reflect.ValueOf(name).Convert(v.Type.Key())
+			result.SetMapIndex(key, val)
+
+		case val.Type().AssignableTo(v.Type.Elem()):
+			result = reflect.Append(result, val)
+
+		default:
+			result = reflect.AppendSlice(result, val)
+		}
+
+		return true
+	}
+
+	// When recording, note what we collect so it can be explained. The
+	// values collected for a vertex are the same for every function, since
+	// a function never depends on a converter that depends on a collection.
+	var collected *collectedValues
+	if state.record {
+		collected = &collectedValues{}
+	}
+
+	// Our direct inputs are all the values that depend on the root.
+	state.lock.Lock()
+	if collected != nil {
+		state.Collected[graph.VertexID(v)] = collected
+	}
+	var inputs []graph.Vertex
+	for _, raw := range g.InEdges(root) {
+		switch in := raw.(type) {
+		case *valueVertex:
+			if in.Value.IsValid() {
+				inputs = append(inputs, in)
+			}
+
+		case *typedOutputVertex:
+			if in.Value.IsValid() {
+				inputs = append(inputs, in)
 			}
 		}
+	}
+	sort.Slice(inputs, func(i, j int) bool {
+		return graph.VertexID(inputs[i]).(string) < graph.VertexID(inputs[j]).(string)
+	})
+	for _, in := range inputs {
+		// The context of the call is only collected by collections of
+		// contexts, since it is provided implicitly by CallContext.
+		if graph.VertexID(in) == contextVertexID && v.Type.Elem() != contextType {
+			continue
+		}
 
-		// We should always have a final value, because our execution to
-		// this point only leads up to this value.
-		if !finalValue.IsValid() {
-			panic(fmt.Sprintf("didn't reach a final value for path: %#v", path))
+		val := in.(valueConverter).value()
+		if add(val.Name, val.Value) {
+			state.InputSet[graph.VertexID(in)] = in
+			if collected != nil {
+				collected.Inputs = append(collected.Inputs, val)
+			}
 		}
+	}
+	state.lock.Unlock()
 
-		// We store the final value in the input map.
-		log.Trace("final value", "vertex", path[len(path)-1], "value", finalValue.Interface())
-		argMap[graph.VertexID(path[len(path)-1])] = finalValue
+	if redefine {
+		return result, nil
 	}
 
-	// Reached our goal
-	return argMap, nil
+	// Find all the converters that produce a value we want.
+	var convs []*funcVertex
+	for _, raw := range g.Vertices() {
+		// We never call ourself as a converter.
+		conv, ok := raw.(*funcVertex)
+		if !ok || conv.Func.fn.Type() == f.fn.Type() {
+			continue
+		}
+
+		// Pointer adapters only exist to satisfy arguments, so we don't
+		// collect the values they adapt a second time.
+		if conv.Func.adapter || !conv.collectable(g, v) {
+			continue
+		}
+
+		convs = append(convs, conv)
+	}
+	sort.Slice(convs, func(i, j int) bool {
+		// Funcs built with BuildFunc all have the same name, so order
+		// those by type. Types are unique since they identify the vertex.
+		ni, nj := convs[i].Func.Name(), convs[j].Func.Name()
+		if ni != nj {
+			return ni < nj
+		}
+
+		return convs[i].Func.fn.Type().String() < convs[j].Func.fn.Type().String()
+	})
+
+	for _, conv := range convs {
+		r, err := f.callConverter(log, g, root, conv, v, state, redefine)
+		if err != nil {
+			// Only skip converters whose own arguments are unsatisfied.
+			// A converter may fail with an error that wraps an
+			// ErrArgumentUnsatisfied, such as from a nested call, and
+			// that must still fail the call.
+			var failed *ErrConverterFailed
+			var unsatisfied *ErrArgumentUnsatisfied
+			if !errors.As(err, &failed) && errors.As(err, &unsatisfied) {
+				log.Trace("skipping unsatisfied converter", "func", conv.Func.Name())
+				continue
+			}
+
+			return reflect.Value{}, err
+		}
+
+		structVal := conv.Func.output.result(r).out[0]
+		for _, val := range conv.Func.output.values {
+			add(val.Name, structVal.Field(val.index))
+		}
+
+		if collected != nil {
+			state.lock.Lock()
+			collected.Funcs = append(collected.Funcs, conv)
+			state.lock.Unlock()
+		}
+	}
+
+	return result, nil
+}
+
+// dryRun is the result of Func.dryRun.
+type dryRun struct {
+	Graph  graph.Graph
+	Root   graph.Vertex
+	Target graph.Vertex
+	Inputs []graph.Vertex
+	State  *callState
+
+	// Funcs maps the ID of each funcVertex to the original Func, since
+	// the vertices are modified to not call the real functions.
+	Funcs map[interface{}]*Func
+}
+
+// dryRun builds the call graph and walks it the same as Call, except that
+// every converter is replaced with a function that returns zero values and
+// the target function is never called. The paths walked are recorded in
+// the state. This lets us determine how a call would be resolved without
+// calling anything.
+//
+// If the builder is redefining, the graph is walked as Redefine requires.
+func (f *Func) dryRun(builder *argBuilder) (*dryRun, error) {
+	g, vertexRoot, vertexF, vertexI, err := f.callGraph(builder)
+	if err != nil {
+		return nil, err
+	}
+
+	// Modify all the converters to be no-ops that just set the output
+	// values to zero values.
+	funcs := map[interface{}]*Func{}
+	for _, v := range g.Vertices() {
+		switch v := v.(type) {
+		case *funcVertex:
+			funcs[graph.VertexID(v)] = v.Func
+
+			// Copy the func since we're going to modify a field in it.
+			fCopy := *v.Func
+			v.Func = &fCopy
+
+			// Modify the function to be a zero producing function. We
+			// also clear callCtx since that would call the real function
+			// and the cache since it is shared with the real function.
+			// The name is preserved since it is derived from fn.
+			fCopy.name = v.Func.Name()
+			fCopy.fn = fCopy.zeroFunc()
+			fCopy.callCtx = nil
+			fCopy.cache = nil
+		}
+	}
+
+	// Build our call state and attempt to reach our target which is our
+	// function. This will recursively reach various conversion targets
+	// as necessary.
+	state := newCallState()
+	state.record = true
+	_, err = f.reachTarget(builder.logger, &g, vertexRoot, vertexF, state, builder.redefining)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dryRun{
+		Graph:  g,
+		Root:   vertexRoot,
+		Target: vertexF,
+		Inputs: vertexI,
+		State:  state,
+		Funcs:  funcs,
+	}, nil
 }
 
 // call -- the unexported version of Call -- calls the function directly
 // with the given named arguments. This skips the whole graph creation
 // step by requiring args satisfy all required arguments.
-func (f *Func) callDirect(log hclog.Logger, argMap map[interface{}]reflect.Value) Result {
-	// If we have FuncOnce enabled and we've been called before, return
-	// the result we have cached.
-	if f.once && f.onceResult != nil {
-		log.Trace("returning cached result, FuncOnce enabled")
-		return *f.onceResult
-	}
-
+//
+// converter is true if the function is being called as a converter. This
+// is given to any interceptors.
+func (f *Func) callDirect(
+	log hclog.Logger,
+	state *callState,
+	argMap map[interface{}]reflect.Value,
+	converter bool,
+) Result {
 	// Initialize the struct we'll be populating
 	var buildErr error
 	structVal := f.input.newStructValue()
 	for _, val := range f.input.values {
 		arg, ok := argMap[graph.VertexID(val.vertex())]
+		if !ok && val.optional {
+			// Optional values that couldn't be satisfied are set to
+			// their default value, or their zero value if there is none.
+			log.Trace("optional argument not satisfied", "arg", val.String(),
+				"default", val.defaultValue.IsValid())
+			structVal.Field(val.index).Set(val.fieldValue(val.defaultValue))
+			continue
+		}
 		if !ok {
 			// This should never happen because we catch unsatisfied errors
 			// earlier in the process. Because of this, we output a message
@@ -584,7 +1102,7 @@ func (f *Func) callDirect(log hclog.Logger, argMap map[interface{}]reflect.Value
 			continue
 		}
 
-		structVal.Field(val.index).Set(arg)
+		structVal.Field(val.index).Set(val.fieldValue(arg))
 	}
 
 	// If there was an error setting up the struct, then report that.
@@ -592,37 +1110,206 @@ func (f *Func) callDirect(log hclog.Logger, argMap map[interface{}]reflect.Value
 		return Result{buildErr: buildErr}
 	}
 
-	// Call our function
+	// If we're tracing, the invocation (and any interceptors) is a span.
+	if state.tracer != nil {
+		name := SpanTarget
+		if converter {
+			name = SpanConverter
+		}
+
+		_, span := startSpan(state.tracer, state.traceCtx, name,
+			"func", f.Name(), "inputs", valueStrings(f.input.withValues(structVal)))
+		result := f.intercepted(log, state, structVal, converter)
+		if result.Err() != nil {
+			span.End(result.Err())
+		} else {
+			span.End(nil, "outputs", valueStrings(result.values()))
+		}
+
+		return result
+	}
+
+	return f.intercepted(log, state, structVal, converter)
+}
+
+// intercepted invokes the function wrapped by the interceptors of the
+// call, if there are any.
+func (f *Func) intercepted(
+	log hclog.Logger,
+	state *callState,
+	structVal *structValue,
+	converter bool,
+) Result {
+	if len(state.interceptors) > 0 {
+		return checkIntercepted(f, intercept(state.interceptors, CallInfo{
+			Context:   state.Context,
+			Func:      f,
+			Input:     f.input.withValues(structVal),
+			Converter: converter,
+		}, func() Result {
+			return f.invoke(log, state, structVal)
+		}))
+	}
+
+	return f.invoke(log, state, structVal)
+}
+
+// invoke calls the function with the populated input struct, using the
+// cached result if the function is cached.
+func (f *Func) invoke(log hclog.Logger, state *callState, structVal *structValue) Result {
+	// If we have no cache then we always call the function.
+	if f.cache == nil {
+		return f.call(log, state, structVal)
+	}
+
+	// Determine our cache key. For FuncOnce this is always nil.
+	var key interface{}
+	if f.cacheKey != nil {
+		key = f.cacheKey(f.input.withValues(structVal))
+		if key != nil && !reflect.TypeOf(key).Comparable() {
+			return resultError(fmt.Errorf(
+				"memoize key for function %q is not comparable: %T", f.Name(), key))
+		}
+	}
+
+	return f.cache.do(key, true, func() Result {
+		return f.call(log, state, structVal)
+	})
+}
+
+// call calls the underlying function with the populated input struct.
+// If the call recovers panics, a panic is returned as an *ErrPanic.
+func (f *Func) call(log hclog.Logger, state *callState, structVal *structValue) (result Result) {
+	if state.recoverPanics {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Trace("function panicked", "func", f.Name(), "value", r)
+				result = resultError(&ErrPanic{
+					Func:  f,
+					Value: r,
+					Stack: debug.Stack(),
+				})
+			}
+		}()
+	}
+
 	in := structVal.CallIn()
 	for i, arg := range in {
 		log.Trace("argument", "idx", i, "value", arg.Interface())
 	}
 
-	out := f.fn.Call(in)
-	result := Result{out: out}
+	var out []reflect.Value
+	switch {
+	case f.callCtx != nil:
+		out = f.callCtx(state.Context, in)
 
-	// If we have FuncOnce enabled, cache the result.
-	if f.once {
-		f.onceResult = &result
+	case f.fn.Type().IsVariadic():
+		// Our variadic parameter is always given as a slice.
+		out = f.fn.CallSlice(in)
+
+	default:
+		out = f.fn.Call(in)
 	}
 
-	return result
+	// If the function returns a cleanup, register it with the call and
+	// remove it from our outputs so the result only has the outputs and
+	// any error.
+	if f.cleanup != nil {
+		idx := len(out) - 1
+		if out[idx].Type() == errType {
+			idx--
+		}
+
+		if fn := cleanupFunc(out[idx]); fn != nil {
+			state.cleanups.push(fn)
+		}
+
+		out = append(out[:idx:idx], out[idx+1:]...)
+	}
+
+	return Result{out: out, output: f.output}
 }
 
 // callState is the shared state for the execution of a single call.
+//
+// The state may be used by multiple goroutines if the call is parallel
+// (see Parallel). lock must be held to access the maps in the state as
+// well as the values of any vertices in the graph being walked.
 type callState struct {
+	lock sync.Mutex
+
 	// NamedValue holds the current table of known named values.
 	NamedValue map[string]reflect.Value
 
 	// TypedValue holds the current table of assigned typed values.
 	TypedValue map[reflect.Type]reflect.Value
 
-	// Value is the last seen value vertex. This state is preserved so
-	// we can set the typedVertex values properly.
-	Value reflect.Value
-
 	// TODO
 	InputSet map[interface{}]graph.Vertex
+
+	// Calls tracks the converters called so that each converter is
+	// called at most once per call.
+	Calls resultGroup
+
+	// Called is the list of converters that were called successfully,
+	// in the order they completed.
+	Called []*Func
+
+	// Context is the context for the call. This may be nil if the call
+	// was made without a context.
+	Context context.Context
+
+	// Paths caches the shortest paths for the graph being walked. This
+	// is usually fresh for each call but may be shared across calls that
+	// walk copies of the same graph (see Plan).
+	Paths *pathCache
+
+	// workers limits the number of goroutines used to walk paths in
+	// parallel. This is nil if the call isn't parallel.
+	workers chan struct{}
+
+	// lazy is set once a Lazy value is created for the call. Lazy values
+	// may be resolved from any goroutine, so paths may be walked
+	// concurrently from then on even if the call isn't parallel.
+	lazy atomic.Bool
+
+	// Walked is the list of paths walked to reach the requirements of
+	// each function. Collected maps the ID of each collect vertex to the
+	// values collected for it. These are only populated if record is true.
+	Walked    []walkedPath
+	Collected map[interface{}]*collectedValues
+	record    bool
+
+	// interceptors wrap the invocation of every function in the call.
+	interceptors []InterceptorFunc
+
+	// tracer traces the call, if set. traceCtx is the context of the
+	// SpanCall span that all other spans of the call are started with.
+	tracer   SpanTracer
+	traceCtx context.Context
+
+	// recoverPanics turns panics in called functions into errors.
+	recoverPanics bool
+
+	// cleanups are the cleanup functions returned by the functions called.
+	cleanups *cleanupStack
+}
+
+// walkedPath is a path that was walked to reach a requirement of Target.
+// The last vertex of the path is the requirement.
+type walkedPath struct {
+	Target graph.Vertex
+	Path   []graph.Vertex
+}
+
+// collectedValues is what was collected for a collect vertex.
+type collectedValues struct {
+	// Inputs are the direct inputs collected, in the order collected.
+	Inputs []*Value
+
+	// Funcs are the vertices of the converters whose outputs were
+	// collected, in the order called.
+	Funcs []graph.Vertex
 }
 
 func newCallState() *callState {
@@ -630,5 +1317,135 @@ func newCallState() *callState {
 		NamedValue: map[string]reflect.Value{},
 		TypedValue: map[reflect.Type]reflect.Value{},
 		InputSet:   map[interface{}]graph.Vertex{},
+		Collected:  map[interface{}]*collectedValues{},
+		Paths:      newPathCache(),
+		cleanups:   &cleanupStack{},
+	}
+}
+
+// finish sets the cleanups of the call on the result so they can be run
+// with Result.Close. If the call failed, the cleanups are run immediately
+// instead since the values they clean up were never used.
+func (s *callState) finish(log hclog.Logger, result Result) Result {
+	if result.Err() != nil {
+		if err := s.cleanups.run(); err != nil {
+			log.Warn("error running cleanups of failed call", "err", err)
+		}
+
+		return result
+	}
+
+	result.cleanups = s.cleanups
+	return result
+}
+
+// setWorkers configures the state to walk paths with up to n goroutines,
+// including the calling goroutine. If n is less than two then paths are
+// walked sequentially.
+func (s *callState) setWorkers(n int) {
+	if n > 1 {
+		s.workers = make(chan struct{}, n-1)
+	}
+}
+
+// concurrent returns true if paths may be walked by multiple goroutines
+// at once. Converters called while this is true wait for a call of the
+// same converter in progress rather than calling it again.
+func (s *callState) concurrent() bool {
+	return s.workers != nil || s.lazy.Load()
+}
+
+// acquireWorker returns true if another goroutine may be started to walk a
+// path. If this returns true, releaseWorker must be called when the
+// goroutine completes.
+func (s *callState) acquireWorker() bool {
+	if s.workers == nil {
+		return false
+	}
+
+	select {
+	case s.workers <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// releaseWorker releases a worker acquired with acquireWorker.
+func (s *callState) releaseWorker() {
+	<-s.workers
+}
+
+// checkContext returns an error if the context for this call is done.
+// f is the function that is about to be called.
+func (s *callState) checkContext(f *Func) error {
+	if s.Context == nil {
+		return nil
+	}
+
+	if err := s.Context.Err(); err != nil {
+		return &ErrCallCanceled{Func: f, Err: err}
+	}
+
+	return nil
+}
+
+// resultGroup ensures a function is only called once for a given key.
+// Concurrent callers with the same key wait for the first call to complete
+// and share its result.
+type resultGroup struct {
+	lock  sync.Mutex
+	calls map[interface{}]*resultCall
+}
+
+// resultCall is an in-flight or completed call in a resultGroup.
+type resultCall struct {
+	done   chan struct{}
+	result Result
+}
+
+// do calls fn and returns its result, unless fn was already called for
+// the same key, in which case the result of the previous call is returned.
+//
+// If wait is false and a call for the key is still in progress, fn is
+// called again rather than waiting. This is used when calls are made from
+// a single goroutine, since a call in progress can only be a recursive
+// call and waiting would deadlock.
+func (g *resultGroup) do(key interface{}, wait bool, fn func() Result) Result {
+	g.lock.Lock()
+	if g.calls == nil {
+		g.calls = map[interface{}]*resultCall{}
+	}
+
+	if c, ok := g.calls[key]; ok {
+		g.lock.Unlock()
+		select {
+		case <-c.done:
+			return c.result
+
+		default:
+			if !wait {
+				return fn()
+			}
+		}
+
+		<-c.done
+		return c.result
 	}
+
+	c := &resultCall{done: make(chan struct{})}
+	g.calls[key] = c
+	g.lock.Unlock()
+
+	c.result = fn()
+	close(c.done)
+	return c.result
+}
+
+// reset forgets all completed calls. Calls in progress complete but their
+// results are not used for later calls.
+func (g *resultGroup) reset() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.calls = nil
 }
@@ -0,0 +1,267 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-argmapper/internal/graph"
+)
+
+// VertexKind is the kind of a vertex in a CallGraph.
+type VertexKind string
+
+const (
+	// VertexRoot is the single root of the graph. Inputs and converters
+	// without any requirements depend on the root.
+	VertexRoot VertexKind = "root"
+
+	// VertexValue is a named value. This is either an input or a named
+	// input or output of a function.
+	VertexValue VertexKind = "value"
+
+	// VertexTypedArg is a typed (unnamed) input of a function.
+	VertexTypedArg VertexKind = "typedArg"
+
+	// VertexTypedOutput is a typed (unnamed) output of a function.
+	VertexTypedOutput VertexKind = "typedOutput"
+
+	// VertexFunc is the target function or a converter.
+	VertexFunc VertexKind = "func"
+
+	// VertexCollect is a slice input of a function, such as a variadic
+	// parameter, that collects all the available values of its element type,
+	// or a map input that collects all the available named values.
+	VertexCollect VertexKind = "collect"
+)
+
+// CallGraph is the graph used to call a function, as returned by
+// Func.Graph. This is meant for debugging why a call resolved its arguments
+// the way it did. It can be rendered with Graphviz using DOT or encoded
+// as JSON using encoding/json.
+//
+// Edges point from a vertex to a requirement of that vertex, so the root
+// has no out edges and the target function has no in edges. Vertices and
+// edges that are on the path chosen to reach an argument have OnPath set.
+type CallGraph struct {
+	Vertices []*CallGraphVertex `json:"vertices"`
+	Edges    []*CallGraphEdge   `json:"edges"`
+}
+
+// CallGraphVertex is a single vertex in a CallGraph.
+type CallGraphVertex struct {
+	// ID uniquely identifies this vertex in the graph.
+	ID string `json:"id"`
+
+	// Kind is the kind of the vertex.
+	Kind VertexKind `json:"kind"`
+
+	// Label is a human-friendly description of the vertex.
+	Label string `json:"label"`
+
+	// Name, Type, and Subtype describe the value that this vertex
+	// represents. For VertexFunc vertices, Name is the name of the function
+	// and Type is the function type. These are empty for the root.
+	Name    string `json:"name,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Subtype string `json:"subtype,omitempty"`
+
+	// OnPath is true if the vertex is on a path used to reach an argument.
+	OnPath bool `json:"onPath"`
+}
+
+// CallGraphEdge is a single edge in a CallGraph.
+type CallGraphEdge struct {
+	// From and To are the IDs of the vertices this edge connects.
+	From string `json:"from"`
+	To   string `json:"to"`
+
+	// Weight is the weight of the edge. Paths with a lower total weight
+	// are preferred.
+	Weight int `json:"weight"`
+
+	// OnPath is true if the edge is on a path used to reach an argument.
+	OnPath bool `json:"onPath"`
+}
+
+// Graph returns the graph that Call would use to call this function with
+// the given args, along with the paths that would be used to reach each
+// argument.
+//
+// No converters or the function itself are called to build the graph. If
+// the function can't be called with the given args, this returns the same
+// error as Call.
+func (f *Func) Graph(opts ...Arg) (*CallGraph, error) {
+	builder, err := f.argBuilder(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := f.dryRun(builder)
+	if err != nil {
+		return nil, err
+	}
+
+	// Determine all the vertices and edges on our paths. Paths go from
+	// the root to a requirement so edges on the path are reversed.
+	pathVertices := map[interface{}]struct{}{
+		graph.VertexID(run.Target): {},
+	}
+	pathEdges := map[[2]interface{}]struct{}{}
+	for _, walked := range run.State.Walked {
+		pathEdges[[2]interface{}{
+			graph.VertexID(walked.Target),
+			graph.VertexID(walked.Path[len(walked.Path)-1]),
+		}] = struct{}{}
+
+		for i, v := range walked.Path {
+			pathVertices[graph.VertexID(v)] = struct{}{}
+			if i > 0 {
+				pathEdges[[2]interface{}{
+					graph.VertexID(v),
+					graph.VertexID(walked.Path[i-1]),
+				}] = struct{}{}
+			}
+		}
+	}
+
+	g := &run.Graph
+	result := &CallGraph{}
+	ids := map[interface{}]string{}
+	for _, v := range g.Vertices() {
+		cv := newCallGraphVertex(v)
+		_, cv.OnPath = pathVertices[graph.VertexID(v)]
+		ids[graph.VertexID(v)] = cv.ID
+		result.Vertices = append(result.Vertices, cv)
+	}
+	for _, v := range g.Vertices() {
+		for _, out := range g.OutEdges(v) {
+			weight, _ := g.EdgeWeight(v, out)
+			_, onPath := pathEdges[[2]interface{}{
+				graph.VertexID(v), graph.VertexID(out)}]
+
+			result.Edges = append(result.Edges, &CallGraphEdge{
+				From:   ids[graph.VertexID(v)],
+				To:     ids[graph.VertexID(out)],
+				Weight: weight,
+				OnPath: onPath,
+			})
+		}
+	}
+
+	// Sort so that our output is deterministic.
+	sort.Slice(result.Vertices, func(i, j int) bool {
+		return result.Vertices[i].ID < result.Vertices[j].ID
+	})
+	sort.Slice(result.Edges, func(i, j int) bool {
+		ei, ej := result.Edges[i], result.Edges[j]
+		if ei.From != ej.From {
+			return ei.From < ej.From
+		}
+
+		return ei.To < ej.To
+	})
+
+	return result, nil
+}
+
+// DOT returns the graph in the Graphviz DOT format. Vertices and edges
+// on a chosen path are drawn in bold red.
+func (g *CallGraph) DOT() string {
+	var buf bytes.Buffer
+	buf.WriteString("digraph argmapper {\n")
+	buf.WriteString("  rankdir=\"BT\";\n")
+	for _, v := range g.Vertices {
+		attrs := []string{"label=" + strconv.Quote(v.Label)}
+		switch v.Kind {
+		case VertexRoot:
+			attrs = append(attrs, "shape=\"circle\"")
+		case VertexFunc:
+			attrs = append(attrs, "shape=\"box\"")
+		case VertexTypedArg, VertexTypedOutput:
+			attrs = append(attrs, "style=\"dashed\"")
+		}
+		if v.OnPath {
+			attrs = append(attrs, "color=\"red\"", "penwidth=\"2\"")
+		}
+
+		fmt.Fprintf(&buf, "  %s [%s];\n", strconv.Quote(v.ID), strings.Join(attrs, ", "))
+	}
+	for _, e := range g.Edges {
+		attrs := []string{"label=" + strconv.Quote(strconv.Itoa(e.Weight))}
+		if e.OnPath {
+			attrs = append(attrs, "color=\"red\"", "penwidth=\"2\"")
+		}
+
+		fmt.Fprintf(&buf, "  %s -> %s [%s];\n",
+			strconv.Quote(e.From), strconv.Quote(e.To), strings.Join(attrs, ", "))
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// newCallGraphVertex converts a vertex in our internal graph into a
+// CallGraphVertex. OnPath is not set.
+func newCallGraphVertex(raw graph.Vertex) *CallGraphVertex {
+	switch v := raw.(type) {
+	case *rootVertex:
+		return &CallGraphVertex{
+			ID:    "root",
+			Kind:  VertexRoot,
+			Label: "root",
+		}
+
+	case *funcVertex:
+		return &CallGraphVertex{
+			ID:    "func: " + v.Func.fn.Type().String(),
+			Kind:  VertexFunc,
+			Label: v.Func.Name(),
+			Name:  v.Func.Name(),
+			Type:  v.Func.fn.Type().String(),
+		}
+
+	case *valueVertex:
+		return &CallGraphVertex{
+			ID:      "value: " + v.Hashcode().(string),
+			Kind:    VertexValue,
+			Label:   v.value().String(),
+			Name:    v.Name,
+			Type:    v.Type.String(),
+			Subtype: v.Subtype,
+		}
+
+	case *typedArgVertex:
+		return &CallGraphVertex{
+			ID:      v.Hashcode().(string),
+			Kind:    VertexTypedArg,
+			Label:   "arg: " + v.value().String(),
+			Type:    v.Type.String(),
+			Subtype: v.Subtype,
+		}
+
+	case *typedOutputVertex:
+		return &CallGraphVertex{
+			ID:      v.Hashcode().(string),
+			Kind:    VertexTypedOutput,
+			Label:   "out: " + v.value().String(),
+			Type:    v.Type.String(),
+			Subtype: v.Subtype,
+		}
+
+	case *collectVertex:
+		return &CallGraphVertex{
+			ID:    v.Hashcode().(string),
+			Kind:  VertexCollect,
+			Label: v.String(),
+			Type:  v.Type.String(),
+		}
+
+	default:
+		panic(fmt.Sprintf("unknown vertex: %T", raw))
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFuncGraph(t *testing.T) {
+	require := require.New(t)
+
+	var called bool
+	f, err := NewFunc(func(v int) int { return v })
+	require.NoError(err)
+
+	g, err := f.Graph(
+		Typed("42"),
+		Named("unused", 3.5),
+		Converter(func(v string) (int, error) {
+			called = true
+			return strconv.Atoi(v)
+		}),
+		Converter(func(v bool) int { return 1 }),
+	)
+	require.NoError(err)
+	require.False(called)
+
+	vertices := map[string]*CallGraphVertex{}
+	for _, v := range g.Vertices {
+		vertices[v.ID] = v
+	}
+
+	// Verify our vertex kinds
+	require.Equal(VertexRoot, vertices["root"].Kind)
+	require.Equal(VertexTypedArg, vertices["arg: int/"].Kind)
+	require.Equal(VertexTypedOutput, vertices["out: int/"].Kind)
+	require.Equal(VertexFunc, vertices["func: func(int) int"].Kind)
+	require.Equal(VertexValue, vertices["value: unused/float64/"].Kind)
+	require.Contains(vertices["func: func(string) (int, error)"].Name, "TestFuncGraph")
+
+	// Verify the path we chose
+	require.True(vertices["func: func(int) int"].OnPath)
+	require.True(vertices["func: func(string) (int, error)"].OnPath)
+	require.True(vertices["arg: string/"].OnPath)
+	require.True(vertices["out: string/"].OnPath)
+	require.False(vertices["value: unused/float64/"].OnPath)
+
+	// Converters that can't be reached from our inputs are pruned
+	require.Nil(vertices["func: func(bool) int"])
+	require.True(vertices["root"].OnPath)
+
+	var found bool
+	for _, e := range g.Edges {
+		if e.From == "func: func(string) (int, error)" && e.To == "arg: string/" {
+			found = true
+			require.True(e.OnPath)
+			require.Equal(weightTyped, e.Weight)
+		}
+	}
+	require.True(found)
+
+	// Verify our encodings
+	require.Contains(g.DOT(), `"func: func(string) (int, error)" -> "arg: string/" [label="5", color="red", penwidth="2"];`)
+	_, err = json.Marshal(g)
+	require.NoError(err)
+}
+
+func TestFuncGraph_unsatisfied(t *testing.T) {
+	f, err := NewFunc(func(v int) int { return v })
+	require.NoError(t, err)
+
+	_, err = f.Graph(Typed("42"))
+	require.Error(t, err)
+}
@@ -0,0 +1,99 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// Cleanup is a cleanup function returned by a converter or target function.
+// A trailing result of this type is not an output value, instead it is run
+// by Result.Close. See the Func docs on cleanup functions.
+type Cleanup func()
+
+// CleanupErr is the same as Cleanup but for cleanups that can fail.
+type CleanupErr func() error
+
+var (
+	cleanupType    = reflect.TypeOf(Cleanup(nil))
+	cleanupErrType = reflect.TypeOf(CleanupErr(nil))
+)
+
+// isCleanup returns true if t is the type of a cleanup function that can be
+// returned by a Func. Plain func types are not cleanups so that functions
+// can still return them as outputs.
+func isCleanup(t reflect.Type) bool {
+	return t == cleanupType || t == cleanupErrType
+}
+
+// cleanupFunc returns the cleanup function v as a func() error. This returns
+// nil if v is nil.
+func cleanupFunc(v reflect.Value) func() error {
+	if v.IsNil() {
+		return nil
+	}
+
+	switch f := v.Interface().(type) {
+	case CleanupErr:
+		return f
+
+	case Cleanup:
+		return func() error {
+			f()
+			return nil
+		}
+
+	default:
+		// This should never happen since we check for cleanup types
+		// when creating a Func.
+		panic("argmapper: value is not a cleanup function: " + v.Type().String())
+	}
+}
+
+// cleanupValue returns fn as a value of the cleanup type t.
+func cleanupValue(t reflect.Type, fn func() error) reflect.Value {
+	if t == cleanupType {
+		return reflect.ValueOf(Cleanup(func() { _ = fn() }))
+	}
+
+	return reflect.ValueOf(CleanupErr(fn))
+}
+
+// cleanupStack is the list of cleanup functions registered during a call.
+// Cleanups are run in the reverse order they were registered. Since a
+// function is only called after all the functions it depends on, this
+// runs the cleanup of a function before the cleanups of its dependencies.
+type cleanupStack struct {
+	lock  sync.Mutex
+	funcs []func() error
+}
+
+// push registers a cleanup function.
+func (s *cleanupStack) push(f func() error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.funcs = append(s.funcs, f)
+}
+
+// run runs and removes all the registered cleanup functions. Every
+// cleanup is run even if an earlier one fails. The errors of all the
+// failed cleanups are returned.
+func (s *cleanupStack) run() error {
+	s.lock.Lock()
+	funcs := s.funcs
+	s.funcs = nil
+	s.lock.Unlock()
+
+	var result error
+	for i := len(funcs) - 1; i >= 0; i-- {
+		if err := funcs[i](); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,301 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// cleanupRecorder records the order cleanups are run in.
+type cleanupRecorder struct {
+	lock sync.Mutex
+	ran  []string
+}
+
+func (r *cleanupRecorder) cleanup(name string) func() error {
+	return func() error {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		r.ran = append(r.ran, name)
+		return nil
+	}
+}
+
+func (r *cleanupRecorder) Ran() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.ran
+}
+
+func TestFuncCall_cleanup(t *testing.T) {
+	require := require.New(t)
+
+	var r cleanupRecorder
+	f := MustFunc(NewFunc(func(v int) (string, Cleanup) {
+		return strconv.Itoa(v), func() { r.cleanup("target")() }
+	}))
+
+	result := f.Call(
+		Typed("21"),
+		Converter(func(v string) (int, CleanupErr, error) {
+			n, err := strconv.Atoi(v)
+			return n, r.cleanup("atoi"), err
+		}),
+		Converter(func(v int) (int32, CleanupErr) {
+			return int32(v * 2), r.cleanup("double")
+		}),
+		Converter(func(v int32) int {
+			return int(v)
+		}),
+	)
+	require.NoError(result.Err())
+	require.Equal(1, result.Len())
+	require.Equal("21", result.Out(0))
+	require.Empty(r.Ran())
+
+	require.NoError(result.Close())
+	require.Equal([]string{"target", "atoi"}, r.Ran())
+
+	// Cleanups only run once.
+	require.NoError(result.Close())
+	require.Equal([]string{"target", "atoi"}, r.Ran())
+}
+
+func TestFuncCall_cleanupOrder(t *testing.T) {
+	require := require.New(t)
+
+	var r cleanupRecorder
+	f := MustFunc(NewFunc(func(v int32) int32 { return v }))
+
+	result := f.Call(
+		Typed("42"),
+		Converter(func(v string) (int, CleanupErr, error) {
+			n, err := strconv.Atoi(v)
+			return n, r.cleanup("first"), err
+		}),
+		Converter(func(v int) (int32, CleanupErr) {
+			return int32(v), r.cleanup("second")
+		}),
+	)
+	require.NoError(result.Err())
+	require.Equal(int32(42), result.Out(0))
+	require.NoError(result.Close())
+	require.Equal([]string{"second", "first"}, r.Ran())
+}
+
+func TestFuncCall_cleanupFailed(t *testing.T) {
+	require := require.New(t)
+
+	var r cleanupRecorder
+	failed := errors.New("failed")
+	f := MustFunc(NewFunc(func(v int32) int32 { return v }))
+
+	result := f.Call(
+		Typed("42"),
+		Converter(func(v string) (int, CleanupErr, error) {
+			n, err := strconv.Atoi(v)
+			return n, r.cleanup("first"), err
+		}),
+		Converter(func(v int) (int32, CleanupErr, error) {
+			return 0, nil, failed
+		}),
+	)
+	require.ErrorIs(result.Err(), failed)
+	require.Equal([]string{"first"}, r.Ran())
+	require.NoError(result.Close())
+	require.Equal([]string{"first"}, r.Ran())
+}
+
+func TestFuncCall_cleanupError(t *testing.T) {
+	require := require.New(t)
+
+	var r cleanupRecorder
+	failed := errors.New("failed")
+	f := MustFunc(NewFunc(func(v int32) int32 { return v }))
+
+	result := f.Call(
+		Typed("42"),
+		Converter(func(v string) (int, CleanupErr, error) {
+			n, err := strconv.Atoi(v)
+			return n, r.cleanup("first"), err
+		}),
+		Converter(func(v int) (int32, CleanupErr) {
+			return int32(v), func() error { return failed }
+		}),
+	)
+	require.NoError(result.Err())
+
+	// All the cleanups run even if one fails.
+	require.ErrorIs(result.Close(), failed)
+	require.Equal([]string{"first"}, r.Ran())
+}
+
+func TestFuncCall_cleanupParallel(t *testing.T) {
+	require := require.New(t)
+
+	var r cleanupRecorder
+	f := MustFunc(NewFunc(func(a int, b string) string {
+		return strconv.Itoa(a) + b
+	}))
+
+	result := f.Call(
+		Typed(int32(4)),
+		Typed(2.0),
+		Converter(func(v int32) (int, CleanupErr) {
+			return int(v), r.cleanup("int")
+		}),
+		Converter(func(v float64) (string, CleanupErr) {
+			return strconv.FormatFloat(v, 'f', -1, 64), r.cleanup("string")
+		}),
+		Parallel(2),
+	)
+	require.NoError(result.Err())
+	require.Equal("42", result.Out(0))
+	require.NoError(result.Close())
+	require.ElementsMatch([]string{"int", "string"}, r.Ran())
+}
+
+func TestFuncCall_cleanupPlan(t *testing.T) {
+	require := require.New(t)
+
+	var r cleanupRecorder
+	f := MustFunc(NewFunc(func(v int) int { return v }))
+
+	p, err := f.Plan(
+		Typed("42"),
+		Converter(func(v string) (int, CleanupErr, error) {
+			n, err := strconv.Atoi(v)
+			return n, r.cleanup(v), err
+		}),
+	)
+	require.NoError(err)
+	require.Empty(r.Ran())
+
+	result := p.Call(Typed("12"))
+	require.NoError(result.Err())
+	require.Equal(12, result.Out(0))
+	require.NoError(result.Close())
+	require.Equal([]string{"12"}, r.Ran())
+}
+
+// probeFile is a resource that records whether it was closed.
+type probeFile struct {
+	closed bool
+}
+
+func TestConvert_cleanup(t *testing.T) {
+	require := require.New(t)
+
+	open := Converter(func(v string) (*probeFile, Cleanup) {
+		f := &probeFile{}
+		return f, func() { f.closed = true }
+	})
+
+	// The converted value is still usable since its cleanup isn't run.
+	v, err := Convert(reflect.TypeOf((*probeFile)(nil)), Typed("a"), open)
+	require.NoError(err)
+	require.False(v.(*probeFile).closed)
+
+	// ConvertWithCleanup returns the cleanup to run when done.
+	v, cleanup, err := ConvertWithCleanup(reflect.TypeOf((*probeFile)(nil)), Typed("a"), open)
+	require.NoError(err)
+	require.False(v.(*probeFile).closed)
+	require.NoError(cleanup())
+	require.True(v.(*probeFile).closed)
+}
+
+func TestRedefine_cleanup(t *testing.T) {
+	require := require.New(t)
+
+	var r cleanupRecorder
+	f := MustFunc(NewFunc(func(v int) (int, CleanupErr) {
+		return v, r.cleanup("target")
+	}))
+
+	redefined, err := f.Redefine(
+		Converter(func(v string) (int, CleanupErr, error) {
+			n, err := strconv.Atoi(v)
+			return n, r.cleanup("atoi"), err
+		}),
+		FilterInput(FilterType(reflect.TypeOf(""))),
+	)
+	require.NoError(err)
+
+	result := redefined.Call(Typed("42"))
+	require.NoError(result.Err())
+	require.Equal(42, result.Out(0))
+	require.Empty(r.Ran())
+	require.NoError(result.Close())
+	require.Equal([]string{"target", "atoi"}, r.Ran())
+}
+
+func TestNewFunc_cleanupCached(t *testing.T) {
+	_, err := NewFunc(func() (int, Cleanup) {
+		return 42, func() {}
+	}, FuncOnce())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "can't be used with FuncOnce")
+}
+
+func TestTypedFunc_cleanup(t *testing.T) {
+	require := require.New(t)
+
+	var r cleanupRecorder
+	f, err := NewFunc1(func(v int) (int, error) { return v, nil })
+	require.NoError(err)
+
+	v, err := f.Call(
+		Typed("42"),
+		Converter(func(v string) (int, CleanupErr, error) {
+			n, err := strconv.Atoi(v)
+			return n, r.cleanup("atoi"), err
+		}),
+	)
+	require.NoError(err)
+	require.Equal(42, v)
+	require.Equal([]string{"atoi"}, r.Ran())
+
+	// A failed cleanup fails the call.
+	failed := errors.New("failed")
+	_, err = f.Call(
+		Typed("42"),
+		Converter(func(v string) (int, CleanupErr, error) {
+			n, err := strconv.Atoi(v)
+			return n, func() error { return failed }, err
+		}),
+	)
+	require.ErrorIs(err, failed)
+}
+
+func TestFuncCall_plainFuncOutputs(t *testing.T) {
+	require := require.New(t)
+
+	// Plain func results are outputs, not cleanups.
+	f := MustFunc(NewFunc(func() func() error {
+		return func() error { return nil }
+	}, FuncOnce()))
+	result := f.Call()
+	require.NoError(result.Err())
+	require.Equal(1, result.Len())
+
+	// A converter can provide a func to be injected.
+	var called bool
+	f = MustFunc(NewFunc(func(done func()) int {
+		done()
+		return 42
+	}))
+	result = f.Call(
+		Typed(1),
+		Converter(func(v int) func() { return func() { called = true } }),
+	)
+	require.NoError(result.Err())
+	require.Equal(42, result.Out(0))
+	require.True(called)
+}
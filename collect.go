@@ -0,0 +1,32 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import "reflect"
+
+// Collect can be used as the type of a parameter or struct field to collect
+// every available value assignable to T into a single slice. This is
+// equivalent to using a []T struct field with the "collect" option.
+//
+//   func(closers argmapper.Collect[io.Closer]) error {
+//     // ...
+//   }
+//
+// See Struct for more details on how values are collected.
+type Collect[T any] []T
+
+func (Collect[T]) argmapperCollect() {}
+
+// collectInterface is implemented only by Collect so that users can't
+// create their own collect types.
+type collectInterface interface {
+	argmapperCollect()
+}
+
+// isCollect returns true if the given type is a Collect type.
+func isCollect(t reflect.Type) bool {
+	return t.Implements(collectInterfaceType)
+}
+
+var collectInterfaceType = reflect.TypeOf((*collectInterface)(nil)).Elem()
@@ -3,13 +3,22 @@
 
 package argmapper
 
-import "reflect"
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
 
 // Convert converts the input arguments to the given target type. Convert will
 // use any of the available arguments and converters to reach the given target
 // type.
+//
+// Any cleanup functions returned by the converters (see Func) are not run,
+// since the converted value may still depend on them. This is also true
+// for ConvertMulti, ConvertInto, and ConvertTo. Use ConvertWithCleanup to
+// run the cleanups once done with the value.
 func Convert(target reflect.Type, opts ...Arg) (interface{}, error) {
-	out, err := convertMulti([]reflect.Type{target}, opts...)
+	out, _, err := convertMulti([]reflect.Type{target}, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -17,27 +26,131 @@ func Convert(target reflect.Type, opts ...Arg) (interface{}, error) {
 	return out[0].Interface(), nil
 }
 
-// convertMulti is the same as Convert but converts multiple types at one
-// time. This is not currently exposed publicly since it is a slightly more
-// complicated interfaces and callers can call Convert multiple times. But
-// we use this internally for Redefine and FilterOutput.
-func convertMulti(target []reflect.Type, opts ...Arg) ([]reflect.Value, error) {
+// ConvertWithCleanup is the same as Convert but also returns a cleanup that
+// runs the cleanup functions returned by the converters, the same as
+// Result.Close. The cleanup must be called once done with the value. It is
+// nil if there is an error, since the cleanups are already run.
+func ConvertWithCleanup(target reflect.Type, opts ...Arg) (interface{}, CleanupErr, error) {
+	out, cleanup, err := convertMulti([]reflect.Type{target}, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return out[0].Interface(), cleanup, nil
+}
+
+// ConvertContext is the same as Convert but with a context. The context
+// is handled the same as Func.CallContext: it is checked before calling any
+// converter and is provided to any converter that takes a context.Context.
+func ConvertContext(ctx context.Context, target reflect.Type, opts ...Arg) (interface{}, error) {
+	return Convert(target, withContextArgs(ctx, opts)...)
+}
+
+// ConvertMulti is the same as Convert but converts to multiple target types
+// at one time. The result has a value for each target type, in the same
+// order as target.
+//
+// All the targets are reached with a single call, so any converter used to
+// reach multiple targets is only called once. This is preferable to calling
+// Convert for each target.
+func ConvertMulti(target []reflect.Type, opts ...Arg) ([]interface{}, error) {
+	out, _, err := convertValues(target, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]interface{}, len(target))
+	for i, v := range out {
+		result[i] = v.Interface()
+	}
+
+	return result, nil
+}
+
+// convertValues is the same as ConvertMulti but returns the values and the
+// cleanup of the conversion. Unlike convertMulti, the target types don't
+// need to be distinct.
+func convertValues(target []reflect.Type, opts ...Arg) ([]reflect.Value, CleanupErr, error) {
+	// Each type can only be a single argument of a function, so we
+	// convert each distinct type once.
+	distinct := distinctTypes(target)
+	out, cleanup, err := convertMulti(distinct, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	idx := map[reflect.Type]int{}
+	for i, t := range distinct {
+		idx[t] = i
+	}
+
+	result := make([]reflect.Value, len(target))
+	for i, t := range target {
+		result[i] = out[idx[t]]
+	}
+
+	return result, cleanup, nil
+}
+
+// distinctTypes returns the distinct types of ts in the order they first
+// appear.
+func distinctTypes(ts []reflect.Type) []reflect.Type {
+	var result []reflect.Type
+	seen := map[reflect.Type]struct{}{}
+	for _, t := range ts {
+		if _, ok := seen[t]; !ok {
+			seen[t] = struct{}{}
+			result = append(result, t)
+		}
+	}
+
+	return result
+}
+
+// ConvertInto converts the input arguments to populate the struct pointed
+// to by v. The struct must embed Struct and its fields are populated exactly
+// like the fields of a function argument of that type, including named,
+// typed, and subtyped fields. See Struct.
+//
+// All the fields are reached with a single call, so any converter used to
+// reach multiple fields is only called once.
+func ConvertInto(v interface{}, opts ...Arg) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || !isStruct(rv.Elem().Type()) {
+		return fmt.Errorf(
+			"ConvertInto requires a pointer to a struct embedding argmapper.Struct, got %T", v)
+	}
+
+	out, _, err := convertMulti([]reflect.Type{rv.Elem().Type()}, opts...)
+	if err != nil {
+		return err
+	}
+
+	rv.Elem().Set(out[0])
+	return nil
+}
+
+// convertMulti is the same as ConvertMulti but returns the values and a
+// cleanup that closes the result of the conversion. The target types must
+// be distinct.
+func convertMulti(target []reflect.Type, opts ...Arg) ([]reflect.Value, CleanupErr, error) {
 	// The way we get convert to work is that we make a dynamic function
 	// that takes the target type as input, and then call Call on it. This
 	// lets our DI system automatically determine our conversion.
 	f, err := convertFunc(target)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	// Call it
+	// Call it. The cleanups of the converters are only run when the
+	// caller is done with the values.
 	result := f.Call(opts...)
 	if err := result.Err(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Our result is the first result
-	return result.out, nil
+	return result.out, result.Close, nil
 }
 
 // convertFunc constructs a function that takes values of the given target
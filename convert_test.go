@@ -4,8 +4,11 @@
 package argmapper
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"strconv"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -67,3 +70,81 @@ type testInterface interface {
 type testInterfaceImpl struct{}
 
 func (*testInterfaceImpl) Error() string { return "hello" }
+
+func TestConvertContext(t *testing.T) {
+	require := require.New(t)
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	result, err := ConvertContext(ctx, reflect.TypeOf(""),
+		Typed(42),
+		Converter(func(ctx context.Context, v int) string {
+			return ctx.Value(ctxKey{}).(string) + strconv.Itoa(v)
+		}),
+	)
+	require.NoError(err)
+	require.Equal("value42", result)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ConvertContext(canceled, reflect.TypeOf(""),
+		Typed(42),
+		Converter(func(v int) string { return strconv.Itoa(v) }),
+	)
+	require.Error(err)
+	require.True(errors.Is(err, context.Canceled))
+}
+
+func TestConvertMulti(t *testing.T) {
+	require := require.New(t)
+
+	var calls int32
+	result, err := ConvertMulti(
+		[]reflect.Type{reflect.TypeOf(""), reflect.TypeOf(0), reflect.TypeOf("")},
+		Typed("42"),
+		Converter(func(v string) (int, error) {
+			atomic.AddInt32(&calls, 1)
+			return strconv.Atoi(v)
+		}),
+	)
+	require.NoError(err)
+	require.Equal([]interface{}{"42", 42, "42"}, result)
+	require.Equal(int32(1), calls)
+
+	_, err = ConvertMulti([]reflect.Type{reflect.TypeOf(0), reflect.TypeOf(1.5)},
+		Typed(42))
+	require.Error(err)
+}
+
+func TestConvertInto(t *testing.T) {
+	require := require.New(t)
+
+	var calls int32
+	var out struct {
+		Struct
+
+		A     int
+		B     string `argmapper:",typeOnly"`
+		C     int    `argmapper:",typeOnly,subtype=c"`
+		Other bool   `argmapper:",optional"`
+	}
+	err := ConvertInto(&out,
+		Named("a", "12"),
+		TypedSubtype(24, "c"),
+		Converter(func(v string) (int, error) {
+			atomic.AddInt32(&calls, 1)
+			return strconv.Atoi(v)
+		}),
+	)
+	require.NoError(err)
+	require.Equal(12, out.A)
+	require.Equal("12", out.B)
+	require.Equal(24, out.C)
+	require.False(out.Other)
+	require.Equal(int32(1), calls)
+
+	var notStruct struct{ A int }
+	require.Error(ConvertInto(&notStruct, Named("a", 12)))
+	require.Error(ConvertInto(out, Named("a", 12)))
+}
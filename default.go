@@ -0,0 +1,82 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// parseDefault parses the value of a "default" struct tag option into
+// a value of type t. This supports any type implementing
+// encoding.TextUnmarshaler, time.Duration, and the basic kinds.
+func parseDefault(t reflect.Type, s string) (reflect.Value, error) {
+	result := reflect.New(t)
+	if u, ok := result.Interface().(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText([]byte(s)); err != nil {
+			return reflect.Value{}, err
+		}
+
+		return result.Elem(), nil
+	}
+
+	result = result.Elem()
+	if t == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		result.SetInt(int64(d))
+		return result, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		result.SetString(s)
+
+	case reflect.Bool:
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		result.SetBool(v)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(s, 0, t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		result.SetInt(v)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(s, 0, t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		result.SetUint(v)
+
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		result.SetFloat(v)
+
+	default:
+		return reflect.Value{}, fmt.Errorf("default values are not supported for type %s", t)
+	}
+
+	return result, nil
+}
+
+// durationType is handled specially by parseDefault.
+var durationType = reflect.TypeOf(time.Duration(0))
@@ -0,0 +1,50 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDefault(t *testing.T) {
+	type named string
+
+	cases := []struct {
+		Name     string
+		Value    string
+		Expected interface{}
+		Err      string
+	}{
+		{"string", "foo", "foo", ""},
+		{"named string", "foo", named("foo"), ""},
+		{"bool", "true", true, ""},
+		{"int", "-12", int(-12), ""},
+		{"int8 overflow", "300", int8(0), "out of range"},
+		{"uint", "12", uint(12), ""},
+		{"float", "1.5", float64(1.5), ""},
+		{"duration", "1m30s", 90 * time.Second, ""},
+		{"time", "2020-01-02T03:04:05Z", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), ""},
+		{"unsupported", "foo", map[string]int{}, "not supported"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			require := require.New(t)
+
+			v, err := parseDefault(reflect.TypeOf(tt.Expected), tt.Value)
+			if tt.Err != "" {
+				require.Error(err)
+				require.Contains(err.Error(), tt.Err)
+				return
+			}
+
+			require.NoError(err)
+			require.Equal(tt.Expected, v.Interface())
+		})
+	}
+}
@@ -27,6 +27,12 @@ type ErrArgumentUnsatisfied struct {
 
 	// Converters is the list of converter functions available for use.
 	Converters []*Func
+
+	// Cycles is the list of converter cycles that the unsatisfied
+	// arguments depend on. None of the converters in these cycles could
+	// be called because each requires a value from another converter in
+	// the cycle. See Analyze.
+	Cycles [][]*Func
 }
 
 func (e *ErrArgumentUnsatisfied) Error() string {
@@ -65,6 +71,24 @@ func (e *ErrArgumentUnsatisfied) Error() string {
 		}
 	}
 
+	cycles := new(bytes.Buffer)
+	if len(e.Cycles) > 0 {
+		fmt.Fprintf(cycles, `
+==> Converter cycles
+    The unsatisfied arguments can only be reached through the converters
+    below, but each converter in a cycle requires a value produced by
+    another converter in the same cycle. Provide an input for one of the
+    converters to break the cycle.
+
+`)
+	}
+	for i, cycle := range e.Cycles {
+		fmt.Fprintf(cycles, "    - cycle %d:\n", i+1)
+		for _, conv := range cycle {
+			fmt.Fprintf(cycles, "        - %s\n", conv.Name())
+		}
+	}
+
 	return fmt.Sprintf(`
 Argument to function %q could not be satisfied!
 
@@ -97,13 +121,95 @@ for debugging.
     with "<" are outputs.
 
 %s
-`,
+%s`,
 		e.Func.Name(),
 		strings.TrimSuffix(missing.String(), "\n"),
 		strings.TrimSuffix(fullArg.String(), "\n"),
 		strings.TrimSuffix(inputs.String(), "\n"),
 		strings.TrimSuffix(convs.String(), "\n"),
+		cycles.String(),
 	)
 }
 
-var _ error = (*ErrArgumentUnsatisfied)(nil)
+// ErrCallCanceled is returned when the context of a call (see CallContext)
+// is done before the call completes.
+type ErrCallCanceled struct {
+	// Func is the function that was about to be called when the
+	// cancellation was noticed. This may be a converter or the target.
+	Func *Func
+
+	// Err is the error from the context. This is usually context.Canceled
+	// or context.DeadlineExceeded.
+	Err error
+}
+
+func (e *ErrCallCanceled) Error() string {
+	return fmt.Sprintf("call to function %q canceled: %s", e.Func.Name(), e.Err)
+}
+
+// Unwrap returns the context error so that errors.Is can be used to check
+// for context.Canceled and context.DeadlineExceeded.
+func (e *ErrCallCanceled) Unwrap() error {
+	return e.Err
+}
+
+// ErrConverterFailed is returned when a converter returns an error while
+// reaching the arguments for a function.
+type ErrConverterFailed struct {
+	// Func is the converter that returned the error.
+	Func *Func
+
+	// Target is the argument that the converter was called to reach.
+	// This is an argument of the target function or, when converters
+	// are chained, of another converter. The Value field is not set.
+	Target Value
+
+	// Path is the list of converters that were called successfully
+	// before Func, in the order they were called.
+	Path []*Func
+
+	// Err is the error returned by the converter.
+	Err error
+}
+
+func (e *ErrConverterFailed) Error() string {
+	return fmt.Sprintf("converter %q failed while reaching %s: %s",
+		e.Func.Name(), e.Target.String(), e.Err)
+}
+
+// Unwrap returns the error returned by the converter.
+func (e *ErrConverterFailed) Unwrap() error {
+	return e.Err
+}
+
+// ErrPanic is returned when a function panics during a call that
+// recovers panics. See RecoverPanics.
+type ErrPanic struct {
+	// Func is the function that panicked. This may be a converter or the
+	// target.
+	Func *Func
+
+	// Value is the value that was recovered from the panic.
+	Value interface{}
+
+	// Stack is the stack trace of the panic.
+	Stack []byte
+}
+
+func (e *ErrPanic) Error() string {
+	return fmt.Sprintf("function %q panicked: %v", e.Func.Name(), e.Value)
+}
+
+// Unwrap returns the recovered value if it is an error, such as a
+// runtime.Error.
+func (e *ErrPanic) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+var (
+	_ error = (*ErrArgumentUnsatisfied)(nil)
+	_ error = (*ErrCallCanceled)(nil)
+	_ error = (*ErrConverterFailed)(nil)
+	_ error = (*ErrPanic)(nil)
+)
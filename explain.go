@@ -0,0 +1,269 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/hashicorp/go-argmapper/internal/graph"
+)
+
+//go:generate stringer -type=MatchKind
+
+// MatchKind is the reason that an argument was matched to the value that
+// satisfied it. See ArgExplanation.
+type MatchKind uint
+
+const (
+	MatchInvalid MatchKind = iota
+
+	// MatchName is a match of a named argument to a value with the
+	// same name and type.
+	MatchName
+
+	// MatchType is a match to a value with the same type. The name and
+	// subtype of the value, if any, are ignored.
+	MatchType
+
+	// MatchSubtype is a match of a typed argument with a subtype to a
+	// value with the same type and subtype.
+	MatchSubtype
+
+	// MatchDefault is an argument that couldn't be satisfied and uses
+	// the default value from its "default" struct tag option.
+	MatchDefault
+
+	// MatchCollect is a collected argument, such as a variadic argument,
+	// that is built from every input and converter output it accepts.
+	// See ArgExplanation.Collected.
+	MatchCollect
+
+	// MatchOptionalZero is an optional argument that couldn't be
+	// satisfied and has no default value, so it is set to its zero value.
+	MatchOptionalZero
+)
+
+// Explanation describes how a call to a function would be resolved. This is
+// returned by Func.Explain.
+type Explanation struct {
+	// Func is the function being explained.
+	Func *Func
+
+	// Args has an explanation for each argument of Func, in the same
+	// order as Func.Input().Values().
+	Args []*ArgExplanation
+}
+
+// ArgExplanation describes how a single argument would be satisfied.
+type ArgExplanation struct {
+	// Arg is the argument of the function.
+	Arg Value
+
+	// Input is the input value at the start of the chosen path. This is
+	// nil if the path starts with a converter that has no requirements.
+	// For MatchDefault, this is the default value. For MatchOptionalZero,
+	// this is nil. For MatchCollect, this is nil and Collected is set
+	// instead.
+	Input *Value
+
+	// Collected is the list of inputs that would be collected for a
+	// MatchCollect argument, in the order they would be collected. The
+	// converters whose outputs would be collected are in Converters.
+	Collected []*Value
+
+	// Converters is the list of converters that would be called to reach
+	// this argument, in the order they would be called. This includes the
+	// converters needed to reach the requirements of each converter. This
+	// is empty if the argument is satisfied directly by an input.
+	Converters []*Func
+
+	// Cost is the total weight of the chosen path. When multiple paths can
+	// satisfy an argument, the path with the lowest cost is chosen.
+	Cost int
+
+	// Match is why the argument matched the value that satisfied it.
+	Match MatchKind
+}
+
+// Explain explains how Call would satisfy the arguments of this function
+// with the given args, without calling anything. This is useful to determine
+// why a converter was or wasn't used.
+//
+// If the function can't be called with the given args, this returns the
+// same error as Call.
+func (f *Func) Explain(opts ...Arg) (*Explanation, error) {
+	builder, err := f.argBuilder(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := f.dryRun(builder)
+	if err != nil {
+		return nil, err
+	}
+
+	// Index the paths that were walked by the function they reach.
+	walked := map[interface{}][][]graph.Vertex{}
+	for _, w := range run.State.Walked {
+		id := graph.VertexID(w.Target)
+		walked[id] = append(walked[id], w.Path)
+	}
+
+	// Index the paths to our own arguments by the argument.
+	argPaths := map[interface{}][]graph.Vertex{}
+	for _, path := range walked[graph.VertexID(run.Target)] {
+		argPaths[graph.VertexID(path[len(path)-1])] = path
+	}
+
+	// converters returns the converters of the given path, including the
+	// converters needed to reach the requirements of each converter.
+	converters := func(path []graph.Vertex) []*Func {
+		var result []*Func
+		seen := map[interface{}]struct{}{}
+		var visit func([]graph.Vertex)
+		visit = func(path []graph.Vertex) {
+			for _, v := range path {
+				if _, ok := v.(*funcVertex); !ok {
+					continue
+				}
+
+				id := graph.VertexID(v)
+				if _, ok := seen[id]; ok {
+					continue
+				}
+				seen[id] = struct{}{}
+
+				for _, p := range walked[id] {
+					visit(p)
+				}
+
+				result = append(result, run.Funcs[id])
+			}
+		}
+		visit(path)
+
+		return result
+	}
+
+	result := &Explanation{Func: f}
+	for _, v := range f.input.values {
+		// Collected arguments aren't reached by a path. Instead they have
+		// every input and converter output that was collected.
+		if c, ok := run.State.Collected[graph.VertexID(v.vertex())]; ok {
+			result.Args = append(result.Args, &ArgExplanation{
+				Arg:        *v,
+				Collected:  c.Inputs,
+				Converters: converters(c.Funcs),
+				Match:      MatchCollect,
+			})
+
+			continue
+		}
+
+		path, ok := argPaths[graph.VertexID(v.vertex())]
+		if !ok {
+			// Arguments that couldn't be reached use their default value,
+			// if they have one, or otherwise their zero value. Only
+			// optional arguments can be unreachable without an error.
+			switch {
+			case v.defaultValue.IsValid():
+				result.Args = append(result.Args, &ArgExplanation{
+					Arg: *v,
+					Input: &Value{
+						Name:    v.Name,
+						Type:    v.Type,
+						Subtype: v.Subtype,
+						Value:   v.defaultValue,
+					},
+					Match: MatchDefault,
+				})
+
+			case v.optional:
+				result.Args = append(result.Args, &ArgExplanation{
+					Arg:   *v,
+					Match: MatchOptionalZero,
+				})
+			}
+
+			continue
+		}
+
+		req := path[len(path)-1]
+		arg := &ArgExplanation{
+			Arg:   *v,
+			Cost:  run.State.Paths.cost(&run.Graph, run.Root, req),
+			Match: matchKind(path),
+		}
+
+		// Our input is the first vertex after the root.
+		if len(path) > 1 {
+			if v, ok := path[1].(valueConverter); ok {
+				arg.Input = v.value()
+			}
+		}
+
+		arg.Converters = converters(path)
+		result.Args = append(result.Args, arg)
+	}
+
+	return result, nil
+}
+
+// String returns a human-friendly description of the explanation.
+func (e *Explanation) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Call to function %q:\n", e.Func.Name())
+	for _, arg := range e.Args {
+		fmt.Fprintf(&buf, "  - %s\n", arg.Arg.String())
+		if arg.Match == MatchDefault {
+			fmt.Fprintf(&buf, "      default: %v\n", arg.Input.Value.Interface())
+		} else if arg.Match == MatchCollect {
+			for _, in := range arg.Collected {
+				fmt.Fprintf(&buf, "      collected: %s\n", in.String())
+			}
+		} else if arg.Input != nil {
+			fmt.Fprintf(&buf, "      input: %s\n", arg.Input.String())
+		} else {
+			fmt.Fprintf(&buf, "      input: none\n")
+		}
+		for _, conv := range arg.Converters {
+			fmt.Fprintf(&buf, "      converter: %s\n", conv.Name())
+		}
+		fmt.Fprintf(&buf, "      match: %s, cost: %d\n", arg.Match, arg.Cost)
+	}
+
+	return buf.String()
+}
+
+// matchKind determines the MatchKind for a walked path. This is
+// based on the requirement (the last vertex) and the vertex that
+// satisfied it.
+func matchKind(path []graph.Vertex) MatchKind {
+	if len(path) < 2 {
+		return MatchInvalid
+	}
+
+	req, prev := path[len(path)-1], path[len(path)-2]
+	switch req := req.(type) {
+	case *valueVertex:
+		// Named values can be satisfied by any typed output.
+		if _, ok := prev.(*typedOutputVertex); ok {
+			return MatchType
+		}
+
+		return MatchName
+
+	case *typedArgVertex:
+		if v, ok := prev.(valueConverter); ok && req.Subtype != "" &&
+			v.value().Subtype == req.Subtype {
+			return MatchSubtype
+		}
+
+		return MatchType
+
+	default:
+		return MatchInvalid
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFuncExplain(t *testing.T) {
+	type argCase struct {
+		Input      string
+		Converters int
+		Cost       int
+		Match      MatchKind
+	}
+
+	cases := []struct {
+		Name     string
+		Callback interface{}
+		Args     []Arg
+		Expected []argCase
+	}{
+		{
+			"named input",
+			func(in struct {
+				Struct
+
+				A int
+			}) int {
+				return in.A
+			},
+			[]Arg{
+				Named("a", 12),
+			},
+			[]argCase{
+				{`name: "a" (type: int)`, 0, 1, MatchName},
+			},
+		},
+
+		{
+			"typed input",
+			func(v int) int {
+				return v
+			},
+			[]Arg{
+				Typed(12),
+			},
+			[]argCase{
+				{"type: int", 0, 6, MatchType},
+			},
+		},
+
+		{
+			"named input preferred to typed input",
+			func(in struct {
+				Struct
+
+				A int
+			}) int {
+				return in.A
+			},
+			[]Arg{
+				Named("a", 12),
+				Typed(24),
+			},
+			[]argCase{
+				{`name: "a" (type: int)`, 0, 1, MatchName},
+			},
+		},
+
+		{
+			"subtype",
+			func(in struct {
+				Struct
+
+				A int `argmapper:",typeOnly,subtype=foo"`
+			}) int {
+				return in.A
+			},
+			[]Arg{
+				TypedSubtype(12, "foo"),
+			},
+			[]argCase{
+				{"type: int (subtype: foo)", 0, 6, MatchSubtype},
+			},
+		},
+
+		{
+			"converter chain",
+			func(in struct {
+				Struct
+
+				A int
+				B string
+			}) int {
+				return in.A
+			},
+			[]Arg{
+				Named("b", "hello"),
+				Named("a", int8(12)),
+				Converter(func(v int8) int16 { return int16(v) }),
+				Converter(func(v int16) int { return int(v) }),
+			},
+			[]argCase{
+				{`name: "a" (type: int8)`, 2, 30, MatchType},
+				{`name: "b" (type: string)`, 0, 1, MatchName},
+			},
+		},
+
+		{
+			"default value",
+			func(in struct {
+				Struct
+
+				A       int
+				Timeout time.Duration `argmapper:"timeout,default=30s"`
+			}) int {
+				return in.A
+			},
+			[]Arg{
+				Named("a", 12),
+			},
+			[]argCase{
+				{`name: "a" (type: int)`, 0, 1, MatchName},
+				{`name: "timeout" (type: time.Duration)`, 0, 0, MatchDefault},
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			require := require.New(t)
+
+			f, err := NewFunc(tt.Callback)
+			require.NoError(err)
+
+			exp, err := f.Explain(tt.Args...)
+			require.NoError(err)
+			require.Equal(f, exp.Func)
+			require.Len(exp.Args, len(tt.Expected))
+			for i, expected := range tt.Expected {
+				arg := exp.Args[i]
+				require.Equal(f.Input().Values()[i], arg.Arg)
+				require.NotNil(arg.Input)
+				require.Equal(expected.Input, arg.Input.String())
+				require.Len(arg.Converters, expected.Converters)
+				require.Equal(expected.Cost, arg.Cost)
+				require.Equal(expected.Match, arg.Match, exp.String())
+			}
+		})
+	}
+}
+
+func TestFuncExplain_converters(t *testing.T) {
+	require := require.New(t)
+
+	var called bool
+	conv1, err := NewFunc(func(v string) (int, error) {
+		called = true
+		return strconv.Atoi(v)
+	}, FuncName("atoi"))
+	require.NoError(err)
+	conv2, err := NewFunc(func(v int) int64 { return int64(v) }, FuncName("widen"))
+	require.NoError(err)
+
+	f, err := NewFunc(func(v int64) int64 { return v })
+	require.NoError(err)
+
+	exp, err := f.Explain(Typed("42"), ConverterFunc(conv2, conv1))
+	require.NoError(err)
+	require.False(called)
+	require.Len(exp.Args, 1)
+
+	// Converters are in call order and are the real converters.
+	require.Equal([]*Func{conv1, conv2}, exp.Args[0].Converters)
+	require.Contains(exp.String(), "converter: atoi")
+}
+
+func TestFuncExplain_unsatisfied(t *testing.T) {
+	f, err := NewFunc(func(v int) int { return v })
+	require.NoError(t, err)
+
+	_, err = f.Explain(Typed("42"))
+	require.Error(t, err)
+}
+
+func TestFuncExplain_default(t *testing.T) {
+	f, err := NewFunc(func(in struct {
+		Struct
+
+		Timeout time.Duration `argmapper:",default=30s"`
+	}) time.Duration {
+		return in.Timeout
+	})
+	require.NoError(t, err)
+
+	exp, err := f.Explain()
+	require.NoError(t, err)
+	require.Len(t, exp.Args, 1)
+	require.Equal(t, MatchDefault, exp.Args[0].Match)
+	require.Equal(t, 30*time.Second, exp.Args[0].Input.Value.Interface())
+	require.Contains(t, exp.String(), "default: 30s")
+}
+
+func TestFuncExplain_optionalZero(t *testing.T) {
+	f, err := NewFunc(func(in struct {
+		Struct
+
+		A string
+		B int `argmapper:",optional"`
+		C Optional[bool]
+	}) string {
+		return in.A
+	})
+	require.NoError(t, err)
+
+	exp, err := f.Explain(Named("a", "foo"))
+	require.NoError(t, err)
+
+	// Every argument is explained, in the same order as the inputs.
+	require.Len(t, exp.Args, 3)
+	for i, v := range f.Input().Values() {
+		require.Equal(t, v.Name, exp.Args[i].Arg.Name)
+	}
+	require.Equal(t, MatchName, exp.Args[0].Match)
+	require.Equal(t, MatchOptionalZero, exp.Args[1].Match)
+	require.Nil(t, exp.Args[1].Input)
+	require.Equal(t, MatchOptionalZero, exp.Args[2].Match)
+}
+
+func TestFuncExplain_collect(t *testing.T) {
+	require := require.New(t)
+
+	conv, err := NewFunc(func(v string) int { return len(v) }, FuncName("length"))
+	require.NoError(err)
+
+	f, err := NewFunc(func(a string, vs ...int) int { return len(vs) })
+	require.NoError(err)
+
+	exp, err := f.Explain(Typed("foo"), Named("b", 2), ConverterFunc(conv))
+	require.NoError(err)
+	require.Len(exp.Args, 2)
+
+	arg := exp.Args[1]
+	require.Equal(MatchCollect, arg.Match)
+	require.Nil(arg.Input)
+	require.Len(arg.Collected, 1)
+	require.Equal("b", arg.Collected[0].Name)
+	require.Equal([]*Func{conv}, arg.Converters)
+	require.Contains(exp.String(), `collected: name: "b"`)
+}
@@ -4,6 +4,7 @@
 package argmapper
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"runtime"
@@ -23,6 +24,13 @@ import (
 //
 // Structs that do not embed the Struct type are matched as typed.
 //
+// A variadic parameter, such as `opts ...Option`, collects every available
+// value assignable to its element type: all the direct inputs (sorted by
+// name, type, and subtype) followed by the outputs of every converter that
+// produces such a value (sorted by converter name). Inputs and outputs that
+// are slices of the parameter type are flattened. A variadic parameter is
+// always satisfied, even if there are no values to collect.
+//
 // Converter Basics
 //
 // A Func also can act as a converter for another function call when used
@@ -45,12 +53,36 @@ import (
 //
 // A final return type of "error" can be used with converters to signal
 // that conversion failed. If this occurs, the full function call attempt
-// fails and the error is reported to the user.
+// fails and the error is reported to the user wrapped in an
+// *ErrConverterFailed describing which converter failed and why it was
+// called.
 //
 // If there is only one return value and it is of type "error", then this
 // is still considered the error result. A function can't return a non-erroneous
 // error value without returning more than one result value.
 //
+// Cleanup Functions
+//
+// Converters that open resources, such as files or connections, can
+// return a cleanup function of type Cleanup or CleanupErr as their last
+// return value (before any error). The cleanup is not an output value.
+// Instead, it is run by Result.Close once the target function is done
+// with the outputs:
+//
+//   func(path string) (*os.File, argmapper.CleanupErr, error)
+//
+// Results of a plain "func()" or "func() error" type are regular outputs.
+//
+// Cleanups are run in the reverse order they were returned, so the cleanup
+// of a converter runs before the cleanups of the converters it depends on.
+// The target function may also return a cleanup, which is run first. If a
+// call fails, including when a later converter fails, the cleanups that
+// were already returned are run before the call returns.
+//
+// Since Convert and friends only return the converted values, they don't
+// run any cleanups. Use ConvertWithCleanup to run them. A function that
+// returns a cleanup can't be used with FuncOnce or FuncMemoize.
+//
 // Converter Priorities
 //
 // When multiple converters are available to reach some desired type,
@@ -79,8 +111,26 @@ type Func struct {
 	output     *ValueSet
 	callOpts   []Arg
 	name       string
-	once       bool
-	onceResult *Result
+
+	// cache caches the results of calling this function. This is nil
+	// unless FuncOnce or FuncMemoize is set. This is a pointer so that
+	// the cache is shared by copies of the Func.
+	cache    *resultGroup
+	cacheKey MemoizeKeyFunc
+
+	// callCtx, if set, is used to call this function instead of fn. It is
+	// given the context of the call, which may be nil. This lets dynamically
+	// built functions (see Redefine) use the context of the call.
+	callCtx func(context.Context, []reflect.Value) []reflect.Value
+
+	// cleanup is the type of the cleanup function returned by this
+	// function, or nil if it doesn't return one. The cleanup is the last
+	// output before any error.
+	cleanup reflect.Type
+
+	// adapter is true if this is an implicit pointer adapter. Adapters
+	// only satisfy arguments and their outputs are never collected.
+	adapter bool
 }
 
 // MustFunc can be called around NewFunc in order to force success and
@@ -124,19 +174,67 @@ func NewFunc(f interface{}, opts ...Arg) (*Func, error) {
 		numOut -= 1
 	}
 
+	// If the last parameter (before any error) is a cleanup function then
+	// it is also not part of the struct information.
+	var cleanup reflect.Type
+	if numOut >= 1 && isCleanup(ft.Out(numOut-1)) {
+		cleanup = ft.Out(numOut - 1)
+		numOut -= 1
+	}
+
 	outTyp, err := newValueSet(numOut, ft.Out)
 	if err != nil {
 		return nil, err
 	}
+	for _, val := range outTyp.values {
+		if val.optionalType != nil {
+			return nil, fmt.Errorf(
+				"output of type %s: Optional can only be used for inputs", val.fieldType())
+		}
+		if val.lazyType != nil {
+			return nil, fmt.Errorf(
+				"output of type %s: Lazy can only be used for inputs", val.fieldType())
+		}
+		if val.collect {
+			return nil, fmt.Errorf(
+				"output of type %s: collect can only be used for inputs", val.fieldType())
+		}
+		if val.namedMap {
+			return nil, fmt.Errorf(
+				"output of type %s: namedMap can only be used for inputs", val.fieldType())
+		}
+	}
 
-	return &Func{
+	// Variadic parameters collect all the available values of their
+	// element type rather than requiring a slice value.
+	if ft.IsVariadic() {
+		inTyp.values[len(inTyp.values)-1].collect = true
+	}
+
+	result := &Func{
 		fn:       fv,
 		input:    inTyp,
 		output:   outTyp,
 		callOpts: opts,
 		name:     args.funcName,
-		once:     args.funcOnce,
-	}, nil
+		cleanup:  cleanup,
+	}
+
+	// If we're caching results, setup the cache. FuncOnce is equivalent
+	// to memoizing with a constant key.
+	if args.funcOnce || args.funcMemoize != nil {
+		// A cached result would be shared by calls that each run
+		// the cleanup, so the two can't be combined.
+		if cleanup != nil {
+			return nil, fmt.Errorf(
+				"function returning a cleanup can't be used with FuncOnce or FuncMemoize")
+		}
+
+		result.cache = &resultGroup{}
+		result.cacheKey = args.funcMemoize
+	}
+
+	return result, nil
 }
 
 // NewFuncList initializes multiple Funcs at once. This is the same as
@@ -192,6 +290,15 @@ func BuildFunc(input, output *ValueSet, cb func(in, out *ValueSet) error, opts .
 	}).Interface(), opts...)
 }
 
+// ResetCache clears any results cached due to FuncOnce or FuncMemoize.
+// The next call of this function will call the underlying function again.
+// This has no effect if neither option was set.
+func (f *Func) ResetCache() {
+	if f.cache != nil {
+		f.cache.reset()
+	}
+}
+
 // Input returns the input ValueSet for this function, representing the values
 // that this function requires as input.
 func (f *Func) Input() *ValueSet { return f.input }
@@ -260,14 +367,23 @@ func (f *Func) graph(g *graph.Graph, root graph.Vertex, includeOutput bool) grap
 		Func: f,
 	})
 
-	// If we take no arguments, we add this function to the root
-	// so that it isn't pruned.
-	if f.input.empty() {
+	// If we take no arguments, or all our arguments are optional, we add
+	// this function to the root so that it isn't pruned.
+	if f.input.empty() || f.input.optional() {
 		g.AddEdge(vertex, root)
 	}
 
 	// Add all our inputs and add an edge from the func to the input
 	for _, val := range f.input.values {
+		// Collected values are always satisfied, since they can be
+		// empty, so they depend on the root.
+		if val.collected() {
+			collect := g.Add(val.vertex())
+			g.AddEdge(vertex, collect)
+			g.AddEdge(collect, root)
+			continue
+		}
+
 		switch val.Kind() {
 		case ValueNamed:
 			g.AddEdge(vertex, g.Add(&valueVertex{
@@ -334,3 +450,6 @@ func (f *Func) outputValues(r Result, vs []graph.Vertex, state *callState) {
 
 // errType is used for comparison in Spec
 var errType = reflect.TypeOf((*error)(nil)).Elem()
+
+// contextType is used to provide the context given to CallContext.
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
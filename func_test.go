@@ -4,13 +4,21 @@
 package argmapper
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"os"
 	"reflect"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/stretchr/testify/require"
@@ -1166,6 +1174,563 @@ func TestFuncCall(t *testing.T) {
 			[]interface{}{"1"},
 			"",
 		},
+
+		{
+			"variadic with no values",
+			func(vs ...int) int {
+				return len(vs)
+			},
+			[]Arg{
+				Typed("foo"),
+			},
+			[]interface{}{0},
+			"",
+		},
+
+		{
+			"variadic with direct inputs",
+			func(vs ...int) []int {
+				return vs
+			},
+			[]Arg{
+				Typed(1),
+				Named("a", 2),
+				Named("b", 3),
+			},
+			[]interface{}{[]int{2, 3, 1}},
+			"",
+		},
+
+		{
+			"variadic with slice input",
+			func(vs ...int) []int {
+				return vs
+			},
+			[]Arg{
+				Typed([]int{1, 2}),
+				Named("a", 3),
+			},
+			[]interface{}{[]int{3, 1, 2}},
+			"",
+		},
+
+		{
+			"variadic with converters",
+			func(prefix string, vs ...int) string {
+				return prefix + fmt.Sprint(vs)
+			},
+			[]Arg{
+				Typed("v"),
+				Typed(1),
+				Converter(func(v string) int { return len(v) }, FuncName("b")),
+				Converter(func(v int8) int { return int(v) }),
+				Converter(func(v string) []int { return []int{7, 8} }),
+			},
+			[]interface{}{"v[1 1 7 8]"},
+			"",
+		},
+
+		{
+			"variadic with interface element",
+			func(cs ...io.Closer) int {
+				return len(cs)
+			},
+			[]Arg{
+				Typed(io.NopCloser(nil)),
+				Named("other", io.NopCloser(nil)),
+				Typed(12),
+			},
+			[]interface{}{2},
+			"",
+		},
+
+		{
+			"variadic converter",
+			func(v string) string {
+				return v
+			},
+			[]Arg{
+				Typed(1),
+				Named("a", 2),
+				Converter(func(vs ...int) string { return fmt.Sprint(vs) }),
+			},
+			[]interface{}{"[2 1]"},
+			"",
+		},
+
+		{
+			"variadic with failed converter",
+			func(vs ...int) int {
+				return len(vs)
+			},
+			[]Arg{
+				Typed("foo"),
+				Converter(func(v string) (int, error) {
+					// The error wraps an ErrArgumentUnsatisfied but the
+					// converter itself was called, so this must fail.
+					result := MustFunc(NewFunc(func(bool) {})).Call()
+					return 0, result.Err()
+				}),
+			},
+			nil,
+			"failed while reaching",
+		},
+
+		{
+			"collect struct field",
+			func(in struct {
+				Struct
+
+				A  string
+				Vs []int `argmapper:",collect"`
+			}) string {
+				return in.A + fmt.Sprint(in.Vs)
+			},
+			[]Arg{
+				Named("a", "v"),
+				Named("b", 1),
+				Typed(2),
+				Converter(func(v string) int { return len(v) }),
+			},
+			[]interface{}{"v[1 2 1]"},
+			"",
+		},
+
+		{
+			"collect marker type",
+			func(vs Collect[int]) []int {
+				return vs
+			},
+			[]Arg{
+				Named("b", 1),
+				Typed(2),
+			},
+			[]interface{}{[]int{1, 2}},
+			"",
+		},
+
+		{
+			"collect interface implementations from converters",
+			func(in struct {
+				Struct
+
+				Closers []io.Closer `argmapper:",collect"`
+			}) int {
+				return len(in.Closers)
+			},
+			[]Arg{
+				Typed("foo"),
+				Converter(func(v string) io.ReadCloser { return io.NopCloser(nil) }),
+				Converter(func(v string) *os.File { return nil }),
+				Converter(func(v string) []io.Closer { return []io.Closer{nil, nil} }),
+				Converter(func(v string) (int, error) { return 0, nil }),
+			},
+			[]interface{}{4},
+			"",
+		},
+
+		{
+			"named map",
+			func(in struct {
+				Struct
+
+				Vs map[string]int `argmapper:",namedMap"`
+			}) map[string]int {
+				return in.Vs
+			},
+			[]Arg{
+				Named("a", 1),
+				Named("b", 2),
+				Named("c", "foo"),
+				Typed(3),
+				Converter(func(in struct {
+					Struct
+
+					C string
+				}) struct {
+					Struct
+
+					B int
+					D int
+				} {
+					var out struct {
+						Struct
+
+						B int
+						D int
+					}
+					out.B = 20
+					out.D = len(in.C)
+					return out
+				}),
+			},
+			[]interface{}{map[string]int{"a": 1, "b": 20, "d": 3}},
+			"",
+		},
+
+		{
+			"named map empty",
+			func(in struct {
+				Struct
+
+				Vs map[string]io.Closer `argmapper:",namedMap"`
+			}) int {
+				return len(in.Vs)
+			},
+			[]Arg{
+				Typed(io.NopCloser(nil)),
+			},
+			[]interface{}{0},
+			"",
+		},
+
+		{
+			"optional struct field missing",
+			func(in struct {
+				Struct
+
+				A string
+				B int `argmapper:",optional"`
+			}) string {
+				return in.A + strconv.Itoa(in.B)
+			},
+			[]Arg{
+				Named("a", "v"),
+			},
+			[]interface{}{"v0"},
+			"",
+		},
+
+		{
+			"optional struct field through converter",
+			func(in struct {
+				Struct
+
+				A string
+				B int `argmapper:",optional"`
+			}) string {
+				return in.A + strconv.Itoa(in.B)
+			},
+			[]Arg{
+				Named("a", "v"),
+				Converter(func(in struct {
+					Struct
+
+					A string
+				}) struct {
+					Struct
+
+					B int
+				} {
+					var out struct {
+						Struct
+
+						B int
+					}
+					out.B = 12
+					return out
+				}),
+			},
+			[]interface{}{"v12"},
+			"",
+		},
+
+		{
+			"optional type missing",
+			func(v Optional[int]) string {
+				return fmt.Sprint(v)
+			},
+			[]Arg{
+				Typed("foo"),
+			},
+			[]interface{}{"{0 false}"},
+			"",
+		},
+
+		{
+			"optional type satisfied",
+			func(v Optional[int], s string) int {
+				return v.Value + len(s)
+			},
+			[]Arg{
+				Typed("foo"),
+				Typed(12),
+			},
+			[]interface{}{15},
+			"",
+		},
+
+		{
+			"optional type through converter",
+			func(v Optional[int]) string {
+				return fmt.Sprint(v)
+			},
+			[]Arg{
+				Typed("foo"),
+				Converter(func(v string) int { return len(v) }),
+			},
+			[]interface{}{"{3 true}"},
+			"",
+		},
+
+		{
+			"optional type with required argument missing",
+			func(v Optional[int], s string) int {
+				return v.Value
+			},
+			[]Arg{
+				Typed(12),
+			},
+			nil,
+			"could not be satisfied",
+		},
+
+		{
+			"default value",
+			func(in struct {
+				Struct
+
+				A       string
+				Timeout time.Duration `argmapper:"timeout,default=30s"`
+			}) string {
+				return in.A + in.Timeout.String()
+			},
+			[]Arg{
+				Named("a", "v"),
+			},
+			[]interface{}{"v30s"},
+			"",
+		},
+
+		{
+			"default value not used if satisfied",
+			func(in struct {
+				Struct
+
+				A       string
+				Timeout time.Duration `argmapper:"timeout,default=30s"`
+			}) string {
+				return in.A + in.Timeout.String()
+			},
+			[]Arg{
+				Named("a", "v"),
+				Named("timeout", 5*time.Second),
+			},
+			[]interface{}{"v5s"},
+			"",
+		},
+
+		{
+			"default value text unmarshaler",
+			func(in struct {
+				Struct
+
+				Addr net.IP `argmapper:",default=127.0.0.1"`
+			}) string {
+				return in.Addr.String()
+			},
+			[]Arg{},
+			[]interface{}{"127.0.0.1"},
+			"",
+		},
+
+		{
+			"default value with optional type",
+			func(in struct {
+				Struct
+
+				Count Optional[int] `argmapper:",default=0x10"`
+			}) string {
+				return fmt.Sprint(in.Count)
+			},
+			[]Arg{},
+			[]interface{}{"{16 true}"},
+			"",
+		},
+
+		{
+			"pointer adapter dereference",
+			func(v testConfig) string {
+				return v.Name
+			},
+			[]Arg{
+				Typed("foo"),
+				Converter(func(v string) *testConfig { return &testConfig{Name: v} }),
+			},
+			[]interface{}{"foo"},
+			"",
+		},
+
+		{
+			"pointer adapter address",
+			func(in struct {
+				Struct
+
+				Config *testConfig
+			}) string {
+				return in.Config.Name
+			},
+			[]Arg{
+				Named("config", testConfig{Name: "foo"}),
+			},
+			[]interface{}{"foo"},
+			"",
+		},
+
+		{
+			"pointer adapter nil",
+			func(v testConfig) string {
+				return v.Name
+			},
+			[]Arg{
+				Typed((*testConfig)(nil)),
+			},
+			nil,
+			"can't dereference nil *argmapper.testConfig",
+		},
+
+		{
+			"pointer adapter replaced by converter",
+			func(v testConfig) string {
+				return v.Name
+			},
+			[]Arg{
+				Typed(&testConfig{Name: "foo"}),
+				Converter(func(v *testConfig) (testConfig, error) {
+					return testConfig{Name: "converted"}, nil
+				}),
+			},
+			[]interface{}{"converted"},
+			"",
+		},
+
+		{
+			"pointer adapter not preferred",
+			func(v testConfig) string {
+				return v.Name
+			},
+			[]Arg{
+				Typed(&testConfig{Name: "pointer"}),
+				Typed("foo"),
+				Converter(func(v string) testConfig { return testConfig{Name: v} }),
+			},
+			[]interface{}{"foo"},
+			"",
+		},
+
+		{
+			"pointer adapters disabled",
+			func(v testConfig) string {
+				return v.Name
+			},
+			[]Arg{
+				Typed(&testConfig{Name: "foo"}),
+				PointerAdapters(false),
+			},
+			nil,
+			"could not be satisfied",
+		},
+
+		{
+			"pointer adapter loses to a long converter chain",
+			func(v testConfig) string {
+				return v.Name
+			},
+			[]Arg{
+				Typed("chain"),
+				Typed(&testConfig{Name: "pointer"}),
+				Converter(func(v string) testChainA { return testChainA{Name: v} }),
+				Converter(func(v testChainA) testChainB { return testChainB(v) }),
+				Converter(func(v testChainB) testChainC { return testChainC(v) }),
+				Converter(func(v testChainC) testConfig { return testConfig(v) }),
+			},
+			[]interface{}{"chain"},
+			"",
+		},
+
+		{
+			"pointer adapters aren't collected",
+			func(v testConfig, vs ...testConfig) string {
+				return v.Name + strconv.Itoa(len(vs))
+			},
+			[]Arg{
+				Typed(&testConfig{Name: "foo"}),
+			},
+			[]interface{}{"foo0"},
+			"",
+		},
+
+		{
+			"typed as interface",
+			func(w io.Writer) string {
+				return fmt.Sprintf("%T", w)
+			},
+			[]Arg{
+				Typed(&bytes.Buffer{}),
+				TypedAs(&strings.Builder{}, reflect.TypeOf((*io.Writer)(nil)).Elem()),
+				Typed(os.Stdout),
+			},
+			[]interface{}{"*strings.Builder"},
+			"",
+		},
+
+		{
+			"typed as nil interface",
+			func(w io.Writer) bool {
+				return w == nil
+			},
+			[]Arg{
+				TypedAs(nil, reflect.TypeOf((*io.Writer)(nil)).Elem()),
+			},
+			[]interface{}{true},
+			"",
+		},
+
+		{
+			"typed as not assignable",
+			func(w io.Writer) bool {
+				return w == nil
+			},
+			[]Arg{
+				TypedAs(12, reflect.TypeOf((*io.Writer)(nil)).Elem()),
+			},
+			nil,
+			"value of type int is not assignable to io.Writer",
+		},
+
+		{
+			"typed subtype as interface",
+			func(in struct {
+				Struct
+
+				W io.Writer `argmapper:",typeOnly,subtype=out"`
+			}) string {
+				return fmt.Sprintf("%T", in.W)
+			},
+			[]Arg{
+				Typed(&bytes.Buffer{}),
+				TypedSubtypeAs(&strings.Builder{}, reflect.TypeOf((*io.Writer)(nil)).Elem(), "out"),
+			},
+			[]interface{}{"*strings.Builder"},
+			"",
+		},
+
+		{
+			"named as interface",
+			func(in struct {
+				Struct
+
+				W io.Writer
+			}) string {
+				return fmt.Sprintf("%T", in.W)
+			},
+			[]Arg{
+				Named("other", &bytes.Buffer{}),
+				NamedAs("w", &strings.Builder{}, reflect.TypeOf((*io.Writer)(nil)).Elem()),
+			},
+			[]interface{}{"*strings.Builder"},
+			"",
+		},
 	}
 
 	for _, tt := range cases {
@@ -1197,6 +1762,17 @@ func TestFuncCall(t *testing.T) {
 // This tests a regression we found with argmapper after merging PR #9.
 // This fails with PR #9 merged and passes without it. We added this test
 // so that can revert PR #9 and safely merge a new fix when this also passes.
+// testConfig is used to test the pointer adapters.
+type testConfig struct {
+	Name string
+}
+
+// testChainA, testChainB, and testChainC are used to build a converter
+// chain to testConfig that competes with the pointer adapters.
+type testChainA struct{ Name string }
+type testChainB struct{ Name string }
+type testChainC struct{ Name string }
+
 func TestFuncCall_waypointRepro(t *testing.T) {
 	type aIn struct {
 		Struct
@@ -1295,6 +1871,36 @@ func TestBuildFunc(t *testing.T) {
 	require.Equal(24, output.Typed(intType).Value.Interface())
 }
 
+func TestBuildFunc_collectOrder(t *testing.T) {
+	require := require.New(t)
+
+	intType := reflect.TypeOf(int(0))
+
+	// Funcs built with BuildFunc all have the same name, so the collected
+	// values must be ordered by the converter type instead.
+	conv := func(t reflect.Type, v int) *Func {
+		input, err := NewValueSet([]Value{{Type: t}})
+		require.NoError(err)
+		output, err := NewValueSet([]Value{{Type: intType}})
+		require.NoError(err)
+
+		return MustFunc(BuildFunc(input, output, func(in, out *ValueSet) error {
+			out.Typed(intType).Value = reflect.ValueOf(v)
+			return nil
+		}))
+	}
+	a := conv(reflect.TypeOf(""), 1)
+	b := conv(reflect.TypeOf(false), 2)
+	require.Equal(a.Name(), b.Name())
+
+	f := MustFunc(NewFunc(func(vs ...int) []int { return vs }))
+	for i := 0; i < 20; i++ {
+		result := f.Call(Typed("foo"), Typed(true), ConverterFunc(a, b))
+		require.NoError(result.Err())
+		require.Equal([]int{2, 1}, result.Out(0))
+	}
+}
+
 // This tests a cycle found while working on the Waypoint project.
 // We were using argmapper in a broken way so this should've never
 // worked but it caused an infinite loop instead. This test originally
@@ -1429,3 +2035,559 @@ func TestFunc_defaultOpts(t *testing.T) {
 	require.NoError(t, result.Err())
 	require.Equal(t, "42", result.Out(0))
 }
+
+func TestFuncCallContext(t *testing.T) {
+	t.Run("injects the context", func(t *testing.T) {
+		require := require.New(t)
+
+		type ctxKey struct{}
+		ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+		f, err := NewFunc(func(ctx context.Context, v int) string {
+			return ctx.Value(ctxKey{}).(string) + strconv.Itoa(v)
+		})
+		require.NoError(err)
+
+		result := f.CallContext(ctx,
+			Typed("42"),
+			Converter(func(ctx context.Context, v string) (int, error) {
+				if ctx.Value(ctxKey{}) == nil {
+					return 0, errors.New("no context")
+				}
+
+				return strconv.Atoi(v)
+			}),
+		)
+		require.NoError(result.Err())
+		require.Equal("value42", result.Out(0))
+	})
+
+	t.Run("context takes priority over typed context", func(t *testing.T) {
+		require := require.New(t)
+
+		type ctxKey struct{}
+		ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+		f, err := NewFunc(func(ctx context.Context) interface{} {
+			return ctx.Value(ctxKey{})
+		})
+		require.NoError(err)
+
+		result := f.CallContext(ctx, Typed(context.TODO()))
+		require.NoError(result.Err())
+		require.Equal("value", result.Out(0))
+	})
+
+	t.Run("context isn't collected", func(t *testing.T) {
+		require := require.New(t)
+
+		f, err := NewFunc(func(vs ...interface{}) []interface{} {
+			return vs
+		})
+		require.NoError(err)
+
+		result := f.CallContext(context.Background(), Typed(1))
+		require.NoError(result.Err())
+		require.Equal([]interface{}{1}, result.Out(0))
+
+		// A collection of contexts still collects the context.
+		ctx := context.Background()
+		f, err = NewFunc(func(vs Collect[context.Context]) int {
+			return len(vs)
+		})
+		require.NoError(err)
+
+		result = f.CallContext(ctx)
+		require.NoError(result.Err())
+		require.Equal(1, result.Out(0))
+	})
+
+	t.Run("canceled before the target", func(t *testing.T) {
+		require := require.New(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		called := false
+		f, err := NewFunc(func(v int) { called = true })
+		require.NoError(err)
+
+		result := f.CallContext(ctx, Typed(42))
+		require.Error(result.Err())
+		require.False(called)
+
+		var canceled *ErrCallCanceled
+		require.True(errors.As(result.Err(), &canceled))
+		require.Equal(f, canceled.Func)
+		require.True(errors.Is(result.Err(), context.Canceled))
+	})
+
+	t.Run("canceled between converters", func(t *testing.T) {
+		require := require.New(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var calls []string
+		f, err := NewFunc(func(v int) { calls = append(calls, "target") })
+		require.NoError(err)
+
+		result := f.CallContext(ctx,
+			Typed(true),
+			Converter(func(v bool) string {
+				calls = append(calls, "bool")
+				cancel()
+				return "42"
+			}),
+			Converter(func(v string) (int, error) {
+				calls = append(calls, "string")
+				return strconv.Atoi(v)
+			}),
+		)
+		require.Error(result.Err())
+		require.True(errors.Is(result.Err(), context.Canceled))
+		require.Equal([]string{"bool"}, calls)
+	})
+}
+
+func TestFuncCall_parallel(t *testing.T) {
+	t.Run("independent converters run concurrently", func(t *testing.T) {
+		require := require.New(t)
+
+		// Each converter waits for the other to start. If the converters
+		// are called sequentially, this times out.
+		var started sync.WaitGroup
+		started.Add(2)
+		wait := func() error {
+			started.Done()
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				started.Wait()
+			}()
+
+			select {
+			case <-done:
+				return nil
+			case <-time.After(5 * time.Second):
+				return errors.New("converters not called in parallel")
+			}
+		}
+
+		f, err := NewFunc(func(in struct {
+			Struct
+
+			A string
+			B int
+		}) string {
+			return in.A + strconv.Itoa(in.B)
+		})
+		require.NoError(err)
+
+		result := f.Call(
+			Parallel(2),
+			Converter(func() (struct {
+				Struct
+
+				A string
+			}, error) {
+				return struct {
+					Struct
+
+					A string
+				}{A: "value"}, wait()
+			}),
+			Converter(func() (struct {
+				Struct
+
+				B int
+			}, error) {
+				return struct {
+					Struct
+
+					B int
+				}{B: 42}, wait()
+			}),
+		)
+		require.NoError(result.Err())
+		require.Equal("value42", result.Out(0))
+	})
+
+	t.Run("shared converter called once", func(t *testing.T) {
+		require := require.New(t)
+
+		var calls int32
+		f, err := NewFunc(func(in struct {
+			Struct
+
+			A string
+			B int
+		}) string {
+			return in.A + strconv.Itoa(in.B)
+		})
+		require.NoError(err)
+
+		result := f.Call(
+			Parallel(4),
+			Typed(int8(21)),
+			Converter(func(v int8) int16 {
+				atomic.AddInt32(&calls, 1)
+				time.Sleep(10 * time.Millisecond)
+				return int16(v)
+			}),
+			Converter(func(v int16) struct {
+				Struct
+
+				A string
+			} {
+				return struct {
+					Struct
+
+					A string
+				}{A: strconv.Itoa(int(v))}
+			}),
+			Converter(func(v int16) struct {
+				Struct
+
+				B int
+			} {
+				return struct {
+					Struct
+
+					B int
+				}{B: int(v) * 2}
+			}),
+		)
+		require.NoError(result.Err())
+		require.Equal("2142", result.Out(0))
+		require.Equal(int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("converter error", func(t *testing.T) {
+		require := require.New(t)
+
+		f, err := NewFunc(func(a string, b int) {})
+		require.NoError(err)
+
+		result := f.Call(
+			Parallel(2),
+			Converter(func() (string, error) { return "", errors.New("failed") }),
+			Converter(func() int { return 42 }),
+		)
+		require.Error(result.Err())
+		require.Contains(result.Err().Error(), "failed")
+	})
+
+	t.Run("invalid worker count", func(t *testing.T) {
+		f, err := NewFunc(func() {})
+		require.NoError(t, err)
+
+		result := f.Call(Parallel(0))
+		require.Error(t, result.Err())
+	})
+}
+
+func TestFuncOnce_concurrent(t *testing.T) {
+	require := require.New(t)
+
+	var calls int32
+	conv, err := NewFunc(func() (int, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(10 * time.Millisecond)
+		return 0, errors.New("failed")
+	}, FuncOnce())
+	require.NoError(err)
+
+	f, err := NewFunc(func(v int) int { return v })
+	require.NoError(err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// Every call should see the error from the single call.
+			result := f.Call(ConverterFunc(conv))
+			require.Error(result.Err())
+			require.Contains(result.Err().Error(), "failed")
+		}()
+	}
+	wg.Wait()
+	require.Equal(int32(1), atomic.LoadInt32(&calls))
+
+	// Resetting the cache should call the function again.
+	conv.ResetCache()
+	result := f.Call(ConverterFunc(conv))
+	require.Error(result.Err())
+	require.Equal(int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestFuncMemoize(t *testing.T) {
+	require := require.New(t)
+
+	var calls int32
+	conv, err := NewFunc(func(v string) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		return strconv.Atoi(v)
+	}, FuncMemoize(func(in *ValueSet) interface{} {
+		return in.Typed(reflect.TypeOf("")).Value.Interface()
+	}))
+	require.NoError(err)
+
+	f, err := NewFunc(func(v int) int { return v * 2 })
+	require.NoError(err)
+
+	call := func(v string) interface{} {
+		result := f.Call(Typed(v), ConverterFunc(conv))
+		require.NoError(result.Err())
+		return result.Out(0)
+	}
+
+	require.Equal(2, call("1"))
+	require.Equal(2, call("1"))
+	require.Equal(int32(1), atomic.LoadInt32(&calls))
+
+	require.Equal(4, call("2"))
+	require.Equal(2, call("1"))
+	require.Equal(int32(2), atomic.LoadInt32(&calls))
+
+	conv.ResetCache()
+	require.Equal(2, call("1"))
+	require.Equal(int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestFuncMemoize_invalidKey(t *testing.T) {
+	require := require.New(t)
+
+	f, err := NewFunc(func(v int) int { return v }, FuncMemoize(func(in *ValueSet) interface{} {
+		return []int{1}
+	}))
+	require.NoError(err)
+
+	result := f.Call(Typed(1))
+	require.Error(result.Err())
+	require.Contains(result.Err().Error(), "not comparable")
+
+	_, err = NewFunc(func() {}, FuncMemoize(nil))
+	require.Error(err)
+}
+
+func TestFuncCall_converterFailed(t *testing.T) {
+	require := require.New(t)
+
+	errSentinel := errors.New("error")
+	conv1, err := NewFunc(func(v string) int8 { return int8(len(v)) })
+	require.NoError(err)
+	conv2, err := NewFunc(func(v int8) (int, error) { return 0, errSentinel })
+	require.NoError(err)
+
+	f, err := NewFunc(func(in struct {
+		Struct
+
+		A int
+	}) int {
+		return in.A
+	})
+	require.NoError(err)
+
+	result := f.Call(Typed("hello"), ConverterFunc(conv1, conv2))
+	require.Error(result.Err())
+	require.True(errors.Is(result.Err(), errSentinel))
+
+	var convErr *ErrConverterFailed
+	require.True(errors.As(result.Err(), &convErr))
+	require.Equal(conv2, convErr.Func)
+	require.Equal("a", convErr.Target.Name)
+	require.Equal(reflect.TypeOf(int(0)), convErr.Target.Type)
+	require.Equal([]*Func{conv1}, convErr.Path)
+	require.Contains(convErr.Error(), `name: "a" (type: int)`)
+}
+
+func TestNewFunc_collectInvalid(t *testing.T) {
+	_, err := NewFunc(func(in struct {
+		Struct
+
+		A int `argmapper:",collect"`
+	}) {
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "collect requires a slice")
+}
+
+func TestNewFunc_namedMapInvalid(t *testing.T) {
+	_, err := NewFunc(func(in struct {
+		Struct
+
+		A map[int]string `argmapper:",namedMap"`
+	}) {
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "namedMap requires a map with string keys")
+}
+
+func TestNewFunc_defaultInvalid(t *testing.T) {
+	_, err := NewFunc(func(in struct {
+		Struct
+
+		A int `argmapper:",default=foo"`
+	}) {
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "field A: invalid default value")
+
+	_, err = NewFunc(func(in struct {
+		Struct
+
+		A []int `argmapper:",default=foo"`
+	}) {
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not supported for type []int")
+}
+
+func TestNewFunc_optionalOutput(t *testing.T) {
+	_, err := NewFunc(func() Optional[int] {
+		return Optional[int]{}
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Optional can only be used for inputs")
+}
+
+func TestNewFunc_collectOutput(t *testing.T) {
+	_, err := NewFunc(func() Collect[int] {
+		return nil
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "collect can only be used for inputs")
+
+	_, err = NewFunc(func() struct {
+		Struct
+
+		A []int `argmapper:",collect"`
+	} {
+		return struct {
+			Struct
+
+			A []int `argmapper:",collect"`
+		}{}
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "collect can only be used for inputs")
+
+	_, err = NewFunc(func() struct {
+		Struct
+
+		A map[string]int `argmapper:",namedMap"`
+	} {
+		return struct {
+			Struct
+
+			A map[string]int `argmapper:",namedMap"`
+		}{}
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "namedMap can only be used for inputs")
+}
+
+func TestFuncCall_recoverPanics(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Callback  interface{}
+		Args      []Arg
+		Panicked  string
+		Converter bool
+	}{
+		{
+			"target",
+			func(v int) int { panic("boom") },
+			[]Arg{Typed(42)},
+			"target",
+			false,
+		},
+
+		{
+			"converter",
+			func(v int) int { return v },
+			[]Arg{
+				Typed("42"),
+				ConverterFunc(MustFunc(NewFunc(func(v string) int {
+					panic("boom")
+				}, FuncName("conv")))),
+			},
+			"conv",
+			true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			require := require.New(t)
+
+			f, err := NewFunc(tt.Callback, FuncName("target"))
+			require.NoError(err)
+
+			result := f.Call(append(tt.Args, RecoverPanics())...)
+			require.Error(result.Err())
+
+			var panicErr *ErrPanic
+			require.ErrorAs(result.Err(), &panicErr)
+			require.Equal(tt.Panicked, panicErr.Func.Name())
+			require.Equal("boom", panicErr.Value)
+			require.NotEmpty(panicErr.Stack)
+
+			var convErr *ErrConverterFailed
+			require.Equal(tt.Converter, errors.As(result.Err(), &convErr))
+		})
+	}
+}
+
+func TestFuncCall_recoverPanicsRuntimeError(t *testing.T) {
+	f := MustFunc(NewFunc(func(v []int) int { return v[1] }))
+
+	result := f.Call(Typed([]int{}), RecoverPanics())
+	var runtimeErr runtime.Error
+	require.ErrorAs(t, result.Err(), &runtimeErr)
+}
+
+func TestFuncCall_noRecoverPanics(t *testing.T) {
+	f := MustFunc(NewFunc(func(v int) int { panic("boom") }))
+	require.PanicsWithValue(t, "boom", func() { f.Call(Typed(42)) })
+}
+
+func TestFuncCall_converterGenError(t *testing.T) {
+	f := MustFunc(NewFunc(func(v int) int { return v }))
+
+	genErr := errors.New("gen failed")
+	args := []Arg{
+		Typed("42"),
+		ConverterGen(func(v Value) (*Func, error) {
+			return nil, genErr
+		}),
+	}
+
+	result := f.Call(args...)
+	require.ErrorIs(t, result.Err(), genErr)
+
+	_, err := f.Plan(args...)
+	require.ErrorIs(t, err, genErr)
+
+	_, err = Analyze(args...)
+	require.ErrorIs(t, err, genErr)
+}
+
+func TestNewFunc_optionalPointer(t *testing.T) {
+	_, err := NewFunc(func(*Optional[int]) {})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Optional can't be used as a pointer")
+
+	_, err = NewFunc(func(in struct {
+		Struct
+
+		A *Optional[int]
+	}) {
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "field A: Optional can't be used as a pointer")
+}
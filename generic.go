@@ -0,0 +1,130 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// ConvertTo is the same as Convert but the target type is given as a type
+// parameter and the result is of that type.
+//
+//   n, err := argmapper.ConvertTo[int](argmapper.Typed("42"), converters)
+func ConvertTo[T any](opts ...Arg) (T, error) {
+	var result T
+	out, _, err := convertMulti([]reflect.Type{reflect.TypeFor[T]()}, opts...)
+	if err != nil {
+		return result, err
+	}
+
+	reflect.ValueOf(&result).Elem().Set(out[0])
+	return result, nil
+}
+
+// ConvertToContext is the same as ConvertTo but with a context. See
+// ConvertContext.
+func ConvertToContext[T any](ctx context.Context, opts ...Arg) (T, error) {
+	return ConvertTo[T](withContextArgs(ctx, opts)...)
+}
+
+// Out returns the i'th result (zero-indexed) of r as a T. If the call
+// failed, this returns the error of the call. This also returns an error if
+// there is no i'th result or if it isn't assignable to T.
+//
+//   name, err := argmapper.Out[string](f.Call(args...), 0)
+func Out[T any](r Result, i int) (T, error) {
+	var result T
+	if err := r.Err(); err != nil {
+		return result, err
+	}
+
+	if i < 0 || i >= r.Len() {
+		return result, fmt.Errorf("result %d out of range, result has %d values", i, r.Len())
+	}
+
+	out := r.out[i]
+	t := reflect.TypeFor[T]()
+	if !out.Type().AssignableTo(t) {
+		return result, fmt.Errorf("result %d is of type %s, not assignable to %s", i, out.Type(), t)
+	}
+
+	reflect.ValueOf(&result).Elem().Set(out)
+	return result, nil
+}
+
+// Provide specifies a typed argument of type T. This is equivalent to
+// calling TypedAs with v and T, so if T is an interface type the value
+// satisfies requirements for T exactly.
+func Provide[T any](v T) Arg {
+	return TypedAs(v, reflect.TypeFor[T]())
+}
+
+// TypedFunc is a Func with a single result of type R. Call and CallContext
+// return the result directly rather than a Result. The underlying Func can
+// be used anywhere a Func is expected, such as with ConverterFunc.
+//
+// Since the Result isn't returned, Call and CallContext run any cleanup
+// functions returned by the function and its converters before returning,
+// the same as Convert. Call the underlying Func to run them later with
+// Result.Close instead.
+//
+// A TypedFunc is created with NewFunc0, NewFunc1, or NewFunc2.
+type TypedFunc[R any] struct {
+	*Func
+}
+
+// NewFunc0 creates a TypedFunc from a function with no parameters. See NewFunc.
+func NewFunc0[R any](fn func() (R, error), opts ...Arg) (*TypedFunc[R], error) {
+	return newTypedFunc[R](fn, opts...)
+}
+
+// NewFunc1 creates a TypedFunc from a function with one parameter. The
+// parameter may be a struct embedding Struct. See NewFunc.
+func NewFunc1[A, R any](fn func(A) (R, error), opts ...Arg) (*TypedFunc[R], error) {
+	return newTypedFunc[R](fn, opts...)
+}
+
+// NewFunc2 creates a TypedFunc from a function with two parameters. See
+// NewFunc.
+func NewFunc2[A, B, R any](fn func(A, B) (R, error), opts ...Arg) (*TypedFunc[R], error) {
+	return newTypedFunc[R](fn, opts...)
+}
+
+func newTypedFunc[R any](fn interface{}, opts ...Arg) (*TypedFunc[R], error) {
+	f, err := NewFunc(fn, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TypedFunc[R]{Func: f}, nil
+}
+
+// Call calls the function and returns its result. See Func.Call.
+func (f *TypedFunc[R]) Call(opts ...Arg) (R, error) {
+	return typedOut[R](f.Func.Call(opts...))
+}
+
+// CallContext calls the function with a context and returns its result.
+// See Func.CallContext.
+func (f *TypedFunc[R]) CallContext(ctx context.Context, opts ...Arg) (R, error) {
+	return typedOut[R](f.Func.CallContext(ctx, opts...))
+}
+
+// typedOut returns the first result of r as an R and closes r.
+func typedOut[R any](r Result) (R, error) {
+	result, err := Out[R](r, 0)
+	if err != nil {
+		_ = r.Close()
+		return result, err
+	}
+
+	if err := r.Close(); err != nil {
+		var zero R
+		return zero, err
+	}
+
+	return result, nil
+}
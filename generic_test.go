@@ -0,0 +1,138 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertTo(t *testing.T) {
+	require := require.New(t)
+
+	n, err := ConvertTo[int](
+		Typed("42"),
+		Converter(func(v string) (int, error) { return strconv.Atoi(v) }),
+	)
+	require.NoError(err)
+	require.Equal(42, n)
+
+	// Interfaces can be reached through implementations, including nil.
+	v, err := ConvertTo[testInterface](
+		Typed("42"),
+		Converter(func(v string) *testInterfaceImpl { return nil }),
+	)
+	require.NoError(err)
+	require.Equal((*testInterfaceImpl)(nil), v)
+
+	_, err = ConvertTo[int](Typed("42"))
+	require.Error(err)
+}
+
+func TestConvertToContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ConvertToContext[int](ctx,
+		Typed("42"),
+		Converter(func(v string) (int, error) { return strconv.Atoi(v) }),
+	)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestOut(t *testing.T) {
+	f := MustFunc(NewFunc(func(v int) (string, int, error) {
+		if v < 0 {
+			return "", 0, errors.New("negative")
+		}
+
+		return strconv.Itoa(v), v, nil
+	}))
+
+	cases := []struct {
+		Name     string
+		Value    int
+		Index    int
+		Expected string
+		Err      string
+	}{
+		{"first result", 42, 0, "42", ""},
+		{"wrong type", 42, 1, "", "result 1 is of type int, not assignable to string"},
+		{"out of range", 42, 2, "", "result 2 out of range"},
+		{"call error", -1, 0, "", "negative"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			require := require.New(t)
+
+			v, err := Out[string](f.Call(Typed(tt.Value)), tt.Index)
+			if tt.Err != "" {
+				require.Error(err)
+				require.Contains(err.Error(), tt.Err)
+				return
+			}
+
+			require.NoError(err)
+			require.Equal(tt.Expected, v)
+		})
+	}
+}
+
+func TestProvide(t *testing.T) {
+	f, err := NewFunc1(func(v int) (string, error) {
+		return strconv.Itoa(v), nil
+	})
+	require.NoError(t, err)
+
+	v, err := f.Call(Provide(42))
+	require.NoError(t, err)
+	require.Equal(t, "42", v)
+}
+
+func TestTypedFunc(t *testing.T) {
+	require := require.New(t)
+
+	f0, err := NewFunc0(func() (int, error) { return 42, nil })
+	require.NoError(err)
+	n, err := f0.Call()
+	require.NoError(err)
+	require.Equal(42, n)
+
+	// Like NewFunc, a Struct parameter can't be mixed with others.
+	f2, err := NewFunc2(func(in struct {
+		Struct
+
+		A int
+	}, s string) (string, error) {
+		return s + strconv.Itoa(in.A), nil
+	})
+	require.Error(err)
+	require.Nil(f2)
+
+	f1, err := NewFunc1(func(in struct {
+		Struct
+
+		A, B string
+	}) (int, error) {
+		return len(in.A) + len(in.B), nil
+	}, FuncName("length"))
+	require.NoError(err)
+	require.Equal("length", f1.Name())
+
+	n, err = f1.CallContext(context.Background(), Named("a", "x"), Named("b", "yz"))
+	require.NoError(err)
+	require.Equal(3, n)
+
+	// The underlying Func can be used as a converter.
+	f, err := NewFunc1(func(v int) (int, error) { return v * 2, nil })
+	require.NoError(err)
+	n, err = f.Call(Named("a", "x"), Named("b", "yz"), ConverterFunc(f1.Func))
+	require.NoError(err)
+	require.Equal(6, n)
+}
@@ -4,8 +4,10 @@
 package argmapper
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 
 	"github.com/hashicorp/go-argmapper/internal/graph"
 )
@@ -70,6 +72,46 @@ type funcVertex struct {
 func (v *funcVertex) Hashcode() interface{} { return v.Func.fn.Type() }
 func (v *funcVertex) String() string        { return "func: " + v.Func.fn.String() }
 
+// collectable returns true if this function can be called to collect values
+// for the collect vertex c. The function must produce such a value, all of
+// its inputs must be in the graph, and it must not depend on any collected
+// values.
+func (v *funcVertex) collectable(g *graph.Graph, c *collectVertex) bool {
+	produces := false
+	for _, val := range v.Func.output.values {
+		if c.accepts(val.Name, val.Type) {
+			produces = true
+			break
+		}
+	}
+	if !produces {
+		return false
+	}
+
+	for _, val := range v.Func.input.values {
+		if val.collected() {
+			return false
+		}
+
+		if g.Vertex(graph.VertexID(val.vertex())) == nil {
+			return false
+		}
+	}
+
+	// Verify we don't depend on any collected values through other
+	// converters.
+	errCollect := errors.New("collect")
+	err := g.DFS(v, func(v graph.Vertex, next func() error) error {
+		if _, ok := v.(*collectVertex); ok {
+			return errCollect
+		}
+
+		return next()
+	})
+
+	return err == nil
+}
+
 // typedArgVertex represents a typed argument to a function. These have no
 // name and match any matching types.
 type typedArgVertex struct {
@@ -130,15 +172,168 @@ func (v *typedOutputVertex) value() *Value {
 	}
 }
 
+// collectVertex represents a slice argument that collects every available
+// value assignable to the element type of the slice. If Named is true, this
+// is instead a map argument that collects every available named value
+// keyed by name. See Func.collect.
+type collectVertex struct {
+	Type  reflect.Type
+	Named bool
+	Value reflect.Value
+}
+
+func (v *collectVertex) Hashcode() interface{} {
+	if v.Named {
+		return fmt.Sprintf("namedMap: %s", v.Type.String())
+	}
+
+	return fmt.Sprintf("collect: %s", v.Type.String())
+}
+
+func (v *collectVertex) String() string { return v.Hashcode().(string) }
+
+// See valueVertex.value
+func (v *collectVertex) value() *Value {
+	return &Value{
+		Type:  v.Type,
+		Value: v.Value,
+		valueInternal: valueInternal{
+			collect:  !v.Named,
+			namedMap: v.Named,
+		},
+	}
+}
+
+// accepts returns true if a value with the given name and type is
+// collected by this vertex. Values that are slices of the collected type
+// are accepted so they can be flattened. Maps only accept named values.
+func (v *collectVertex) accepts(name string, t reflect.Type) bool {
+	if v.Named {
+		return name != "" && t.AssignableTo(v.Type.Elem())
+	}
+
+	return t.AssignableTo(v.Type.Elem()) || t.AssignableTo(v.Type)
+}
+
 // rootVertex tracks the root of a function call. This should have
 // in-edges only from the inputs. We use this to get a single root.
 type rootVertex struct{}
 
 func (v *rootVertex) String() string { return "root" }
 
+// copyVertex returns a copy of v if v is a vertex that stores a value
+// while walking the graph. Any other vertex is returned as-is. This is used
+// with graph.CopyMap to get a graph that can be walked independently.
+func copyVertex(v graph.Vertex) graph.Vertex {
+	switch v := v.(type) {
+	case *valueVertex:
+		v2 := *v
+		return &v2
+
+	case *typedArgVertex:
+		v2 := *v
+		return &v2
+
+	case *typedOutputVertex:
+		v2 := *v
+		return &v2
+
+	case *collectVertex:
+		v2 := *v
+		return &v2
+
+	default:
+		return v
+	}
+}
+
+// pathCache caches shortest path calculations for a graph. The shortest
+// paths only depend on the structure of the graph and the name of the value
+// (if any) being reached, so they can be calculated once and reused for
+// every target in a graph or in any copy of the graph.
+//
+// pathCache is safe for concurrent use.
+type pathCache struct {
+	lock  sync.Mutex
+	paths map[pathKey]*shortestPaths
+}
+
+// pathKey is the key for cached shortest paths. Paths to value vertices
+// are calculated with a discount for matching names, so the name is part
+// of the key.
+type pathKey struct {
+	named bool
+	name  string
+}
+
+// shortestPaths is the result of a shortest path calculation from the root.
+type shortestPaths struct {
+	distTo map[interface{}]int
+	edgeTo map[interface{}]graph.Vertex
+}
+
+func newPathCache() *pathCache {
+	return &pathCache{
+		paths: map[pathKey]*shortestPaths{},
+	}
+}
+
+// path returns the shortest path from root to current in g. The returned
+// vertices are always the vertices in g, even if the shortest paths were
+// calculated using another copy of the graph.
+func (c *pathCache) path(g *graph.Graph, root, current graph.Vertex) []graph.Vertex {
+	path := g.EdgeToPath(current, c.shortest(g, root, current).edgeTo)
+	for i, v := range path {
+		path[i] = g.Vertex(graph.VertexID(v))
+	}
+
+	return path
+}
+
+// cost returns the total weight of the shortest path from root to current
+// in g.
+func (c *pathCache) cost(g *graph.Graph, root, current graph.Vertex) int {
+	return c.shortest(g, root, current).distTo[graph.VertexID(current)]
+}
+
+// shortest returns the shortest paths to use to reach current.
+func (c *pathCache) shortest(g *graph.Graph, root, current graph.Vertex) *shortestPaths {
+	var key pathKey
+	if v, ok := current.(*valueVertex); ok {
+		key = pathKey{named: true, name: v.Name}
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if result, ok := c.paths[key]; ok {
+		return result
+	}
+
+	// For value vertices, we discount any other values that share the
+	// same name. This lets our shortest paths prefer matching through
+	// same-named arguments.
+	currentG := g
+	if key.named {
+		currentG = currentG.Copy()
+		for _, raw := range currentG.Vertices() {
+			if v, ok := raw.(*valueVertex); ok && v.Name == key.name {
+				for _, src := range currentG.InEdges(raw) {
+					currentG.AddEdgeWeighted(src, raw, weightMatchingName)
+				}
+			}
+		}
+	}
+
+	result := &shortestPaths{}
+	result.distTo, result.edgeTo = currentG.Reverse().Dijkstra(root)
+	c.paths[key] = result
+	return result
+}
+
 var (
 	_ graph.VertexHashable = (*funcVertex)(nil)
 	_ graph.VertexHashable = (*valueVertex)(nil)
 	_ graph.VertexHashable = (*typedArgVertex)(nil)
 	_ graph.VertexHashable = (*typedOutputVertex)(nil)
+	_ graph.VertexHashable = (*collectVertex)(nil)
 )
@@ -0,0 +1,128 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// Injector holds values and converters that are used for many calls. Rather
+// than passing the same set of args to every call, the args can be
+// registered once with an Injector and functions called through it.
+//
+// Injectors can be scoped. A child Injector created with Scope inherits the
+// values and converters of its parent and may register its own. A child
+// value replaces a parent value only if it has the same name, type, and
+// subtype, and a child converter replaces a parent converter only if it has
+// the same function type. Otherwise, the values and converters of both are
+// available and are matched as usual. A typical use is an application scope
+// holding long-lived values with a child scope for each request. Changes to
+// a parent are visible to its children.
+//
+// Only values (Named, Typed, etc.) and converters (Converter, ConverterGen,
+// etc.) are kept by an Injector. Other args, such as Logger, should be
+// given with each call.
+//
+// An Injector is safe for concurrent use.
+type Injector struct {
+	parent *Injector
+
+	lock    sync.RWMutex
+	builder *argBuilder
+}
+
+// NewInjector creates a new Injector with the given values and converters
+// registered.
+func NewInjector(opts ...Arg) (*Injector, error) {
+	return newInjector(nil, opts...)
+}
+
+func newInjector(parent *Injector, opts ...Arg) (*Injector, error) {
+	builder, err := newArgBuilder(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Injector{
+		parent:  parent,
+		builder: builder,
+	}, nil
+}
+
+// Scope creates a child Injector with the given values and converters
+// registered. The child inherits all the registrations of this Injector.
+// Values and converters registered with the child override those of this
+// Injector for calls made through the child and are not visible to this
+// Injector.
+func (i *Injector) Scope(opts ...Arg) (*Injector, error) {
+	return newInjector(i, opts...)
+}
+
+// Register registers additional values and converters. Values replace any
+// existing value with the same name, type, and subtype. Converters replace
+// any existing converter with the same function type.
+//
+// If any arg returns an error, nothing is registered.
+func (i *Injector) Register(opts ...Arg) error {
+	builder, err := newArgBuilder(opts...)
+	if err != nil {
+		return err
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.builder.merge(builder)
+	return nil
+}
+
+// Arg returns an Arg that sets all the values and converters of this
+// Injector, including those inherited from parent scopes. This can be used
+// to use the Injector with other APIs such as Func.Redefine or Func.Plan.
+func (i *Injector) Arg() Arg {
+	return func(a *argBuilder) error {
+		i.mergeInto(a)
+		return nil
+	}
+}
+
+// Call calls f with the values and converters of this Injector. Any extra
+// args are applied after the args of the Injector, so values given here
+// replace any registered values.
+func (i *Injector) Call(f *Func, extra ...Arg) Result {
+	return f.Call(i.args(extra)...)
+}
+
+// CallContext is the same as Call but calls f with Func.CallContext.
+func (i *Injector) CallContext(ctx context.Context, f *Func, extra ...Arg) Result {
+	return f.CallContext(ctx, i.args(extra)...)
+}
+
+// Convert converts to the target type using the values and converters of
+// this Injector. This is the same as the package-level Convert. Any extra
+// args are handled the same as Call.
+func (i *Injector) Convert(t reflect.Type, extra ...Arg) (interface{}, error) {
+	return Convert(t, i.args(extra)...)
+}
+
+// args returns the full list of args to use for a call.
+func (i *Injector) args(extra []Arg) []Arg {
+	result := make([]Arg, 0, len(extra)+1)
+	result = append(result, i.Arg())
+	return append(result, extra...)
+}
+
+// mergeInto merges the registrations of this Injector and its parents into
+// the given builder, starting with the furthest parent so that children
+// take priority.
+func (i *Injector) mergeInto(b *argBuilder) {
+	if i.parent != nil {
+		i.parent.mergeInto(b)
+	}
+
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	b.merge(i.builder)
+}
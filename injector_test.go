@@ -0,0 +1,206 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInjectorCall(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Callback interface{}
+		Parent   []Arg
+		Child    []Arg
+		Extra    []Arg
+		Out      []interface{}
+		Err      string
+	}{
+		{
+			"value from parent",
+			func(v int) int { return v },
+			[]Arg{Typed(12)},
+			nil,
+			nil,
+			[]interface{}{12},
+			"",
+		},
+
+		{
+			"child value overrides parent",
+			func(v int) int { return v },
+			[]Arg{Typed(12)},
+			[]Arg{Typed(24)},
+			nil,
+			[]interface{}{24},
+			"",
+		},
+
+		{
+			"extra value overrides child",
+			func(v int) int { return v },
+			[]Arg{Typed(12)},
+			[]Arg{Typed(24)},
+			[]Arg{Typed(42)},
+			[]interface{}{42},
+			"",
+		},
+
+		{
+			"child value through parent converter",
+			func(v int) int { return v },
+			[]Arg{Converter(func(v string) (int, error) { return strconv.Atoi(v) })},
+			[]Arg{Typed("42")},
+			nil,
+			[]interface{}{42},
+			"",
+		},
+
+		{
+			"child converter overrides parent",
+			func(v int) int { return v },
+			[]Arg{
+				Typed("42"),
+				Converter(func(v string) (int, error) { return strconv.Atoi(v) }),
+			},
+			[]Arg{
+				Converter(func(v string) (int, error) { return len(v), nil }),
+			},
+			nil,
+			[]interface{}{2},
+			"",
+		},
+
+		{
+			"unsatisfied",
+			func(v int) int { return v },
+			[]Arg{Typed("42")},
+			nil,
+			nil,
+			nil,
+			"could not be satisfied",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			require := require.New(t)
+
+			f, err := NewFunc(tt.Callback)
+			require.NoError(err)
+
+			parent, err := NewInjector(tt.Parent...)
+			require.NoError(err)
+			child, err := parent.Scope(tt.Child...)
+			require.NoError(err)
+
+			result := child.Call(f, tt.Extra...)
+
+			// If we expect an error, check that
+			if tt.Err == "" {
+				require.NoError(result.Err())
+			} else {
+				require.Error(result.Err())
+				require.Contains(result.Err().Error(), tt.Err)
+			}
+
+			// Verify outputs
+			require.Equal(len(tt.Out), result.Len())
+			for i, out := range tt.Out {
+				require.Equal(out, result.Out(i))
+			}
+		})
+	}
+}
+
+func TestInjectorScope(t *testing.T) {
+	require := require.New(t)
+
+	f, err := NewFunc(func(v int) int { return v })
+	require.NoError(err)
+
+	parent, err := NewInjector(Typed(12))
+	require.NoError(err)
+	child, err := parent.Scope(Typed(24))
+	require.NoError(err)
+
+	// Child registrations are not visible to the parent
+	result := parent.Call(f)
+	require.NoError(result.Err())
+	require.Equal(12, result.Out(0))
+
+	// Child registrations replace parent registrations of the same type
+	result = child.Call(f)
+	require.NoError(result.Err())
+	require.Equal(24, result.Out(0))
+
+	// Parent registrations made later are visible to the child
+	f, err = NewFunc(func(v int, s string) string { return s + strconv.Itoa(v) })
+	require.NoError(err)
+	require.NoError(parent.Register(Typed("value")))
+	result = child.Call(f)
+	require.NoError(result.Err())
+	require.Equal("value24", result.Out(0))
+}
+
+func TestInjectorRegister_error(t *testing.T) {
+	require := require.New(t)
+
+	inj, err := NewInjector(Typed(12))
+	require.NoError(err)
+
+	// A failing arg registers nothing
+	require.Error(inj.Register(Typed(24), Converter(12)))
+
+	f, err := NewFunc(func(v int) int { return v })
+	require.NoError(err)
+	result := inj.Call(f)
+	require.NoError(result.Err())
+	require.Equal(12, result.Out(0))
+}
+
+func TestInjectorConvert(t *testing.T) {
+	require := require.New(t)
+
+	inj, err := NewInjector(
+		Typed("42"),
+		Converter(func(v string) (int, error) { return strconv.Atoi(v) }),
+	)
+	require.NoError(err)
+
+	v, err := inj.Convert(reflect.TypeOf(int(0)))
+	require.NoError(err)
+	require.Equal(42, v)
+}
+
+func TestInjector_concurrent(t *testing.T) {
+	f, err := NewFunc(func(v int) int { return v })
+	require.NoError(t, err)
+
+	inj, err := NewInjector(Typed(0))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			require.NoError(t, inj.Register(Named(strconv.Itoa(i), i)))
+		}(i)
+		go func() {
+			defer wg.Done()
+			scope, err := inj.Scope()
+			require.NoError(t, err)
+			result := scope.Call(f)
+			require.NoError(t, result.Err())
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,108 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// CallInfo describes a single function invocation for an InterceptorFunc.
+type CallInfo struct {
+	// Context is the context of the call. This is nil if the call was
+	// made without a context.
+	Context context.Context
+
+	// Func is the function being invoked.
+	Func *Func
+
+	// Input is the resolved input values of the function.
+	Input *ValueSet
+
+	// Converter is true if the function is being invoked as a converter
+	// and false if it is the target function of the call.
+	Converter bool
+}
+
+// InterceptorFunc intercepts a function invocation. The interceptor must
+// call next to invoke the function (or the next interceptor) and should
+// typically return its result. An interceptor may also return a different
+// result or not invoke the function at all, for example to deny the call
+// by returning an ErrorResult. A different result must either be an error
+// or have the same outputs as the function, otherwise the call fails.
+// See Interceptor.
+type InterceptorFunc func(info CallInfo, next func() Result) Result
+
+// Interceptor adds an interceptor that wraps the invocation of every
+// converter and the target function of a call. This can be used for logging,
+// metrics, authorization, etc.
+//
+// Interceptors are composed in the order they're given: the first
+// interceptor is the outermost and calls the second, and so on. Interceptors
+// given to Func.Plan are called for every call of the plan, before any
+// interceptors given to Plan.Call.
+//
+// The interceptors are called even if the function result is cached by
+// FuncOnce or FuncMemoize. Interceptors are never called by functions that
+// don't invoke anything, such as Func.Graph, Func.Explain, or Func.Redefine.
+func Interceptor(f InterceptorFunc) Arg {
+	return func(a *argBuilder) error {
+		if f == nil {
+			return errors.New("interceptor cannot be nil")
+		}
+
+		a.interceptors = append(a.interceptors, f)
+		return nil
+	}
+}
+
+// intercept calls fn wrapped by the given interceptors.
+func intercept(interceptors []InterceptorFunc, info CallInfo, fn func() Result) Result {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], fn
+		fn = func() Result {
+			return interceptor(info, next)
+		}
+	}
+
+	return fn()
+}
+
+// checkIntercepted returns r if it is an error or has the outputs of f,
+// and an error result otherwise. r is the result of the interceptors of f,
+// which may not be the result of calling f.
+func checkIntercepted(f *Func, r Result) Result {
+	if r.Err() != nil {
+		return r
+	}
+
+	// The result has every output of f except a cleanup.
+	ft := f.fn.Type()
+	cleanup := -1
+	if f.cleanup != nil {
+		cleanup = ft.NumOut() - 1
+		if ft.Out(cleanup) == errType {
+			cleanup--
+		}
+	}
+	var want []reflect.Type
+	for i := 0; i < ft.NumOut(); i++ {
+		if i != cleanup {
+			want = append(want, ft.Out(i))
+		}
+	}
+
+	valid := len(r.out) == len(want)
+	for i := 0; valid && i < len(want); i++ {
+		valid = r.out[i].IsValid() && r.out[i].Type() == want[i]
+	}
+	if !valid {
+		return resultError(fmt.Errorf(
+			"interceptor returned a result that doesn't match the outputs of %q", f.Name()))
+	}
+
+	return r
+}
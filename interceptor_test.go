@@ -0,0 +1,177 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterceptor(t *testing.T) {
+	require := require.New(t)
+
+	conv := MustFunc(NewFunc(func(v string) (int, error) {
+		return strconv.Atoi(v)
+	}, FuncName("atoi")))
+	f := MustFunc(NewFunc(func(v int) int { return v * 2 }, FuncName("double")))
+
+	var calls []string
+	record := func(prefix string) InterceptorFunc {
+		return func(info CallInfo, next func() Result) Result {
+			calls = append(calls, fmt.Sprintf("%s>%s(%v,%v)",
+				prefix, info.Func.Name(), info.Input.Values()[0].Value.Interface(), info.Converter))
+			result := next()
+			calls = append(calls, fmt.Sprintf("%s<%s", prefix, info.Func.Name()))
+			return result
+		}
+	}
+
+	result := f.Call(
+		Typed("21"),
+		ConverterFunc(conv),
+		Interceptor(record("a")),
+		Interceptor(record("b")),
+	)
+	require.NoError(result.Err())
+	require.Equal(42, result.Out(0))
+	require.Equal([]string{
+		"a>atoi(21,true)",
+		"b>atoi(21,true)",
+		"b<atoi",
+		"a<atoi",
+		"a>double(21,false)",
+		"b>double(21,false)",
+		"b<double",
+		"a<double",
+	}, calls)
+}
+
+func TestInterceptor_deny(t *testing.T) {
+	require := require.New(t)
+
+	var called bool
+	f := MustFunc(NewFunc(func(v int) int {
+		called = true
+		return v
+	}))
+
+	denied := errors.New("denied")
+	result := f.Call(Typed(42), Interceptor(func(info CallInfo, next func() Result) Result {
+		return ErrorResult(denied)
+	}))
+	require.ErrorIs(result.Err(), denied)
+	require.False(called)
+}
+
+func TestInterceptor_invalidResult(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Result func() Result
+	}{
+		{"empty", func() Result { return Result{} }},
+		{"nil error", func() Result { return ErrorResult(nil) }},
+		{"other function", func() Result {
+			return MustFunc(NewFunc(func() string { return "" })).Call()
+		}},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			require := require.New(t)
+
+			f := MustFunc(NewFunc(func(v int) int { return v }))
+			result := f.Call(
+				Typed("42"),
+				Converter(func(v string) (int, error) { return strconv.Atoi(v) }),
+				Interceptor(func(info CallInfo, next func() Result) Result {
+					if info.Converter {
+						return tt.Result()
+					}
+
+					return next()
+				}),
+			)
+			require.Error(result.Err())
+		})
+	}
+}
+
+func TestInterceptor_context(t *testing.T) {
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	f := MustFunc(NewFunc(func(v int) int { return v }))
+
+	var value interface{}
+	result := f.CallContext(ctx, Typed(42), Interceptor(func(info CallInfo, next func() Result) Result {
+		value = info.Context.Value(ctxKey{})
+		return next()
+	}))
+	require.NoError(t, result.Err())
+	require.Equal(t, "value", value)
+}
+
+func TestInterceptor_dryRun(t *testing.T) {
+	require := require.New(t)
+
+	f := MustFunc(NewFunc(func(v int) int { return v }))
+
+	var called bool
+	interceptor := Interceptor(func(info CallInfo, next func() Result) Result {
+		called = true
+		return next()
+	})
+	args := []Arg{
+		Typed("42"),
+		Converter(func(v string) (int, error) { return strconv.Atoi(v) }),
+		interceptor,
+	}
+
+	_, err := f.Explain(args...)
+	require.NoError(err)
+	_, err = f.Graph(args...)
+	require.NoError(err)
+	_, err = f.Redefine(args...)
+	require.NoError(err)
+	require.False(called)
+}
+
+func TestInterceptor_plan(t *testing.T) {
+	require := require.New(t)
+
+	f := MustFunc(NewFunc(func(v int) int { return v }))
+
+	var calls []string
+	record := func(name string) Arg {
+		return Interceptor(func(info CallInfo, next func() Result) Result {
+			calls = append(calls, name)
+			return next()
+		})
+	}
+
+	p, err := f.Plan(Typed(1), record("plan"))
+	require.NoError(err)
+
+	result := p.Call(Typed(2), record("call"))
+	require.NoError(result.Err())
+	require.Equal(2, result.Out(0))
+	require.Equal([]string{"plan", "call"}, calls)
+
+	// Interceptors given to a call aren't kept by the plan.
+	calls = nil
+	result = p.Call()
+	require.NoError(result.Err())
+	require.Equal([]string{"plan"}, calls)
+}
+
+func TestInterceptor_nil(t *testing.T) {
+	f := MustFunc(NewFunc(func(v int) int { return v }))
+	result := f.Call(Typed(42), Interceptor(nil))
+	require.Error(t, result.Err())
+}
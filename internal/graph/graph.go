@@ -110,6 +110,13 @@ func (g *Graph) AddEdgeWeighted(v1, v2 Vertex, weight int) {
 	g.adjacencyIn[h2][h1] = weight
 }
 
+// EdgeWeight returns the weight of the edge from v1 to v2. The boolean is
+// false if there is no such edge.
+func (g *Graph) EdgeWeight(v1, v2 Vertex) (int, bool) {
+	weight, ok := g.adjacencyOut[hashcode(v1)][hashcode(v2)]
+	return weight, ok
+}
+
 func (g *Graph) RemoveEdge(v1, v2 Vertex) {
 	g.init()
 	h1, h2 := hashcode(v1), hashcode(v2)
@@ -183,6 +190,19 @@ func (g *Graph) Copy() *Graph {
 	return &g2
 }
 
+// CopyMap is the same as Copy but also replaces every vertex in the copy
+// with the result of calling fn with that vertex. fn must return a vertex
+// with the same hashcode as the vertex it was given. This can be used to
+// copy vertices that hold state so the copy can be modified independently.
+func (g *Graph) CopyMap(fn func(Vertex) Vertex) *Graph {
+	g2 := g.Copy()
+	for k, v := range g2.hash {
+		g2.hash[k] = fn(v)
+	}
+
+	return g2
+}
+
 // String outputs some human-friendly output for the graph structure.
 func (g *Graph) String() string {
 	var buf bytes.Buffer
@@ -0,0 +1,141 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// Lazy can be used as the type of a parameter or struct field to reach the
+// value only when it is needed. The value is matched as a T, but any
+// converters required to produce it aren't called until Get is called.
+// This is useful for expensive values that are only used some of the time.
+//
+//   func(client argmapper.Lazy[*api.Client]) error {
+//     if !needClient {
+//       return nil
+//     }
+//
+//     c, err := client.Get()
+//     // ...
+//   }
+//
+// A Lazy value is still required: the call fails if no input or converter
+// can produce a T. See Struct for more details.
+type Lazy[T any] struct {
+	value *lazyValue
+}
+
+// Get returns the value, calling any converters required to produce it the
+// first time it is called. Later calls return the same value and error.
+// Get is safe for concurrent use, and converters shared with other Lazy
+// values of the same call are still called at most once.
+//
+// The converters are called with the context of the original call, so Get
+// returns an error if the context is done. Any cleanup functions returned
+// by the converters are run by Result.Close of the original call.
+func (l Lazy[T]) Get() (T, error) {
+	var result T
+	if l.value == nil {
+		return result, errors.New("lazy value was not set by a call")
+	}
+
+	v, err := l.value.resolve()
+	if err != nil {
+		return result, err
+	}
+	if v.IsValid() {
+		reflect.ValueOf(&result).Elem().Set(v)
+	}
+
+	return result, nil
+}
+
+func (Lazy[T]) argmapperLazy(v *lazyValue) interface{} { return Lazy[T]{value: v} }
+
+func (l Lazy[T]) argmapperLazyValue() *lazyValue { return l.value }
+
+// lazyInterface is implemented only by Lazy so that users can't create
+// their own lazy types. This also lets us create and inspect Lazy values
+// without knowing T.
+type lazyInterface interface {
+	argmapperLazy(*lazyValue) interface{}
+	argmapperLazyValue() *lazyValue
+}
+
+// lazyValue is the value shared by copies of a Lazy value.
+type lazyValue struct {
+	lock  sync.Mutex
+	get   func() (reflect.Value, error)
+	value reflect.Value
+	err   error
+}
+
+// resolve returns the value, calling get if it hasn't been called yet.
+func (v *lazyValue) resolve() (reflect.Value, error) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if v.get != nil {
+		v.value, v.err = v.get()
+		v.get = nil
+	}
+
+	return v.value, v.err
+}
+
+// peek returns the value if it was already resolved, or an invalid value
+// if it wasn't or is being resolved.
+func (v *lazyValue) peek() reflect.Value {
+	if !v.lock.TryLock() {
+		return reflect.Value{}
+	}
+	defer v.lock.Unlock()
+
+	if v.get != nil || v.err != nil {
+		return reflect.Value{}
+	}
+
+	return v.value
+}
+
+// isLazy returns true if the given type is a Lazy type. This is false for
+// a pointer to a Lazy type, see isLazyPtr.
+func isLazy(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.Implements(lazyInterfaceType)
+}
+
+// isLazyPtr returns true if the given type is a pointer to a Lazy type.
+// These are not supported since a Lazy value is already shared by copies.
+func isLazyPtr(t reflect.Type) bool {
+	return t.Kind() == reflect.Ptr && isLazy(t.Elem())
+}
+
+// lazyElem returns the type T of the Lazy type t.
+func lazyElem(t reflect.Type) reflect.Type {
+	m, _ := t.MethodByName("Get")
+	return m.Type.Out(0)
+}
+
+// newLazy returns a value of the Lazy type t that calls get to resolve
+// its value.
+func newLazy(t reflect.Type, get func() (reflect.Value, error)) reflect.Value {
+	lazy := reflect.Zero(t).Interface().(lazyInterface)
+	return reflect.ValueOf(lazy.argmapperLazy(&lazyValue{get: get}))
+}
+
+// lazyFieldValue returns the value of the Lazy value v if it was already
+// resolved, or an invalid value otherwise.
+func lazyFieldValue(v reflect.Value) reflect.Value {
+	lv := v.Interface().(lazyInterface).argmapperLazyValue()
+	if lv == nil {
+		return reflect.Value{}
+	}
+
+	return lv.peek()
+}
+
+var lazyInterfaceType = reflect.TypeOf((*lazyInterface)(nil)).Elem()
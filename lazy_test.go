@@ -0,0 +1,258 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLazy(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Get    bool
+		Result string
+		Calls  int32
+	}{
+		{
+			"not used",
+			false,
+			"unused",
+			0,
+		},
+
+		{
+			"used",
+			true,
+			"42",
+			1,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			require := require.New(t)
+
+			var calls int32
+			f := MustFunc(NewFunc(func(v Lazy[int]) (string, error) {
+				if !tt.Get {
+					return "unused", nil
+				}
+
+				// Get is only called once even if called multiple times.
+				if _, err := v.Get(); err != nil {
+					return "", err
+				}
+				n, err := v.Get()
+				return strconv.Itoa(n), err
+			}))
+
+			result := f.Call(
+				Typed("42"),
+				Converter(func(v string) (int, error) {
+					atomic.AddInt32(&calls, 1)
+					return strconv.Atoi(v)
+				}),
+			)
+			require.NoError(result.Err())
+			require.Equal(tt.Result, result.Out(0))
+			require.Equal(tt.Calls, atomic.LoadInt32(&calls))
+		})
+	}
+}
+
+func TestLazy_direct(t *testing.T) {
+	require := require.New(t)
+
+	f := MustFunc(NewFunc(func(in struct {
+		Struct
+
+		A Lazy[int]
+	}) (int, error) {
+		return in.A.Get()
+	}))
+
+	result := f.Call(Named("a", 42))
+	require.NoError(result.Err())
+	require.Equal(42, result.Out(0))
+}
+
+func TestLazy_error(t *testing.T) {
+	require := require.New(t)
+
+	failed := errors.New("failed")
+	f := MustFunc(NewFunc(func(v Lazy[int]) error {
+		_, err := v.Get()
+		return err
+	}))
+
+	result := f.Call(
+		Typed("42"),
+		Converter(func(v string) (int, error) { return 0, failed }),
+	)
+	require.ErrorIs(result.Err(), failed)
+
+	var convErr *ErrConverterFailed
+	require.ErrorAs(result.Err(), &convErr)
+}
+
+func TestLazy_unsatisfied(t *testing.T) {
+	var called bool
+	f := MustFunc(NewFunc(func(v Lazy[int]) {
+		called = true
+	}))
+
+	result := f.Call(Typed("42"))
+	require.Error(t, result.Err())
+	require.False(t, called)
+}
+
+func TestLazy_afterCall(t *testing.T) {
+	require := require.New(t)
+
+	// A lazy value can be used after the call, such as by a goroutine
+	// started by the target.
+	var calls int32
+	var lazy Lazy[int]
+	g := MustFunc(NewFunc(func(v Lazy[int]) { lazy = v }))
+	result := g.CallContext(context.Background(),
+		Typed("42"),
+		Converter(func(v string) (int, Cleanup, error) {
+			atomic.AddInt32(&calls, 1)
+			n, err := strconv.Atoi(v)
+			return n, func() { atomic.AddInt32(&calls, 100) }, err
+		}),
+	)
+	require.NoError(result.Err())
+	require.Equal(int32(0), atomic.LoadInt32(&calls))
+
+	values := make([]int, 4)
+	errs := make([]error, 4)
+	var wg sync.WaitGroup
+	for i := range values {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values[i], errs[i] = lazy.Get()
+		}()
+	}
+	wg.Wait()
+	require.Equal([]int{42, 42, 42, 42}, values)
+	require.Equal(make([]error, 4), errs)
+	require.Equal(int32(1), atomic.LoadInt32(&calls))
+
+	// The cleanup of the converter is run when closing the result.
+	require.NoError(result.Close())
+	require.Equal(int32(101), atomic.LoadInt32(&calls))
+}
+
+func TestLazy_sharedConverter(t *testing.T) {
+	require := require.New(t)
+
+	type base struct{ N int }
+
+	// Both lazy values need the base converter. Resolving them
+	// concurrently must still only call it once.
+	var calls int32
+	f := MustFunc(NewFunc(func(a Lazy[int32], b Lazy[int64]) (int64, error) {
+		var wg sync.WaitGroup
+		var av int32
+		var bv int64
+		var aerr, berr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			av, aerr = a.Get()
+		}()
+		go func() {
+			defer wg.Done()
+			bv, berr = b.Get()
+		}()
+		wg.Wait()
+
+		if err := errors.Join(aerr, berr); err != nil {
+			return 0, err
+		}
+		return int64(av) + bv, nil
+	}))
+
+	result := f.Call(
+		Typed(21),
+		Converter(func(v int) base {
+			atomic.AddInt32(&calls, 1)
+			time.Sleep(10 * time.Millisecond)
+			return base{N: v}
+		}),
+		Converter(func(v base) int32 { return int32(v.N) }),
+		Converter(func(v base) int64 { return int64(v.N) }),
+	)
+	require.NoError(result.Err())
+	require.Equal(int64(42), result.Out(0))
+	require.Equal(int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestLazy_zero(t *testing.T) {
+	var v Lazy[int]
+	_, err := v.Get()
+	require.Error(t, err)
+}
+
+func TestLazy_explain(t *testing.T) {
+	require := require.New(t)
+
+	var called bool
+	f := MustFunc(NewFunc(func(v Lazy[int]) {}))
+
+	e, err := f.Explain(
+		Typed("42"),
+		Converter(func(v string) (int, error) {
+			called = true
+			return strconv.Atoi(v)
+		}),
+	)
+	require.NoError(err)
+	require.False(called)
+	require.Len(e.Args[0].Converters, 1)
+}
+
+func TestNewFunc_lazyOptional(t *testing.T) {
+	_, err := NewFunc(func(in struct {
+		Struct
+
+		A Lazy[int] `argmapper:",optional"`
+	}) {
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Lazy values can't be optional")
+}
+
+func TestNewFunc_lazyOutput(t *testing.T) {
+	_, err := NewFunc(func() Lazy[int] {
+		return Lazy[int]{}
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Lazy can only be used for inputs")
+}
+
+func TestNewFunc_lazyPointer(t *testing.T) {
+	_, err := NewFunc(func(*Lazy[int]) {})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Lazy can't be used as a pointer")
+
+	_, err = NewFunc(func(in struct {
+		Struct
+
+		A *Lazy[int]
+	}) {
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "field A: Lazy can't be used as a pointer")
+}
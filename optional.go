@@ -0,0 +1,73 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import "reflect"
+
+// Optional can be used as the type of a parameter or struct field to make
+// the value optional. The value is matched as a T. If no input or converter
+// can produce the value, Valid is false instead of the call failing.
+//
+//   func(timeout argmapper.Optional[time.Duration]) error {
+//     if !timeout.Valid {
+//       // ...
+//     }
+//   }
+//
+// This is equivalent to using the "optional" struct tag option, except that
+// it can be used for plain parameters and a missing value can be told apart
+// from a zero value. See Struct for more details.
+type Optional[T any] struct {
+	// Value is the value of the argument. This is the zero value if
+	// Valid is false.
+	Value T
+
+	// Valid is true if the argument was satisfied.
+	Valid bool
+}
+
+func (Optional[T]) argmapperOptional() {}
+
+// optionalInterface is implemented only by Optional so that users can't
+// create their own optional types.
+type optionalInterface interface {
+	argmapperOptional()
+}
+
+// isOptional returns true if the given type is an Optional type. This is
+// false for a pointer to an Optional type, see isOptionalPtr.
+func isOptional(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.Implements(optionalInterfaceType)
+}
+
+// isOptionalPtr returns true if the given type is a pointer to an Optional
+// type. These are not supported since the Optional type already tells
+// whether the value is set.
+func isOptionalPtr(t reflect.Type) bool {
+	return t.Kind() == reflect.Ptr && isOptional(t.Elem())
+}
+
+// newOptional returns a value of the Optional type t with the given value.
+// If v is not valid, the result is not valid.
+func newOptional(t reflect.Type, v reflect.Value) reflect.Value {
+	result := reflect.New(t).Elem()
+	if v.IsValid() {
+		result.Field(0).Set(v)
+		result.Field(1).SetBool(true)
+	}
+
+	return result
+}
+
+// optionalValue returns the value of the Optional value v, or an invalid
+// value if v is not valid.
+func optionalValue(v reflect.Value) reflect.Value {
+	if !v.Field(1).Bool() {
+		return reflect.Value{}
+	}
+
+	return v.Field(0)
+}
+
+var optionalInterfaceType = reflect.TypeOf((*optionalInterface)(nil)).Elem()
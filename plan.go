@@ -0,0 +1,183 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/go-argmapper/internal/graph"
+)
+
+// ErrPlanMismatch is returned (wrapped) by Plan.Call when the args given
+// do not match the inputs and converters that the Plan was built with.
+// Use errors.Is to check for this error.
+var ErrPlanMismatch = errors.New("args do not match the plan")
+
+// Plan is a precomputed call to a Func. Building a Plan resolves the call
+// graph and the shortest converter paths for a set of inputs once. The Plan
+// can then be called any number of times with new values for those inputs
+// without the cost of rebuilding the graph and recalculating paths.
+//
+// The "shape" of the inputs, meaning the name, type, and subtype of each
+// value, as well as the set of converters are fixed when the Plan is built.
+// Only the values themselves can change between calls.
+//
+// A Plan is safe for concurrent use.
+type Plan struct {
+	f      *Func
+	g      *graph.Graph
+	root   graph.Vertex
+	target graph.Vertex
+	inputs map[interface{}]struct{}
+	paths  *pathCache
+
+	// interceptors are the interceptors given when building the plan.
+	interceptors []InterceptorFunc
+
+	// tracer is the tracer given when building the plan, if any.
+	tracer SpanTracer
+
+	// recoverPanics is true if RecoverPanics was given when building
+	// the plan.
+	recoverPanics bool
+}
+
+// Plan builds a Plan for calling this function. The opts must contain
+// every converter the plan may use and a value for every input. The values
+// given here are the defaults for Plan.Call, so a call only needs to
+// specify the values that change.
+//
+// An error is returned if the function can't be called with the given
+// args. This is the same error that Call would return.
+func (f *Func) Plan(opts ...Arg) (*Plan, error) {
+	builder, err := f.argBuilder(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	g, vertexRoot, vertexF, vertexI, err := f.callGraph(builder)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := map[interface{}]struct{}{}
+	for _, v := range vertexI {
+		inputs[graph.VertexID(v)] = struct{}{}
+	}
+
+	p := &Plan{
+		f:      f,
+		g:      &g,
+		root:   vertexRoot,
+		target: vertexF,
+		inputs: inputs,
+		paths:  newPathCache(),
+
+		interceptors: builder.interceptors,
+		tracer:       builder.tracer,
+
+		recoverPanics: builder.recoverPanics,
+	}
+
+	// Calculate the paths for every requirement in the graph up front
+	// so that calls only have to walk them.
+	for _, v := range g.Vertices() {
+		if _, ok := v.(*funcVertex); !ok {
+			continue
+		}
+
+		for _, req := range g.OutEdges(v) {
+			p.paths.path(&g, vertexRoot, req)
+		}
+	}
+
+	return p, nil
+}
+
+// Func returns the Func that this plan calls.
+func (p *Plan) Func() *Func { return p.f }
+
+// Call calls the planned function. The opts may replace the value of any
+// input given when building the Plan. Options that don't affect the shape
+// of the inputs, such as Logger, may also be given.
+//
+// If any value doesn't match an input of the plan (a new name, type, or
+// subtype) or any converters are given, this returns an error wrapping
+// ErrPlanMismatch without calling anything. Build a new Plan in this case.
+func (p *Plan) Call(opts ...Arg) Result {
+	builder, err := newArgBuilder(opts...)
+	if err != nil {
+		return resultError(err)
+	}
+	log := builder.logger
+	log.Trace("call plan")
+
+	if len(builder.convs) > 0 || len(builder.convGens) > 0 {
+		return resultError(fmt.Errorf(
+			"%w: converters can't be changed for a plan", ErrPlanMismatch))
+	}
+
+	// Copy our graph so that we have our own vertices to store values in,
+	// then swap in any new values.
+	g := p.g.CopyMap(copyVertex)
+	for _, v := range builder.inputs() {
+		if _, ok := p.inputs[graph.VertexID(v)]; !ok {
+			// The context given to CallContext is only an input if the
+			// plan was built with one.
+			if builder.ctx != nil && graph.VertexID(v) == contextVertexID {
+				continue
+			}
+
+			return resultError(fmt.Errorf(
+				"%w: input %s is not part of the plan",
+				ErrPlanMismatch, newValueFromVertex(v).String()))
+		}
+
+		g.AddOverwrite(v)
+	}
+
+	// A tracer given to the call replaces the tracer of the plan.
+	tracer := p.tracer
+	if builder.tracer != nil {
+		tracer = builder.tracer
+	}
+	traceCtx, span := startSpan(tracer, builder.ctx, SpanCall, "func", p.f.Name())
+
+	// Walk our precomputed paths to get our arguments.
+	state := newCallState()
+	state.Context = builder.ctx
+	state.Paths = p.paths
+	state.interceptors = append(
+		p.interceptors[:len(p.interceptors):len(p.interceptors)],
+		builder.interceptors...)
+	state.tracer = tracer
+	state.traceCtx = traceCtx
+	state.recoverPanics = p.recoverPanics || builder.recoverPanics
+	state.setWorkers(builder.parallel)
+	argMap, err := p.f.reachTarget(log, g, p.root, p.target, state, false)
+	if err == nil {
+		// Verify we haven't been canceled while reaching our target.
+		err = state.checkContext(p.f)
+	}
+	if err != nil {
+		span.End(err)
+		return state.finish(log, resultError(err))
+	}
+
+	result := state.finish(log, p.f.callDirect(log, state, argMap, false))
+	span.End(result.Err())
+	return result
+}
+
+// CallContext is the same as Call but with a context for the call. The
+// context is checked the same as Func.CallContext.
+//
+// The context is provided to any argument of type context.Context only if
+// the plan was built with a context.Context value, such as with TypedAs.
+// That value is replaced by the context given here.
+func (p *Plan) CallContext(ctx context.Context, opts ...Arg) Result {
+	return p.Call(withContextArgs(ctx, opts)...)
+}
@@ -0,0 +1,273 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanCall(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Callback interface{}
+		PlanArgs []Arg
+		CallArgs []Arg
+		Out      []interface{}
+		Err      string
+	}{
+		{
+			"defaults from plan",
+			func(in struct {
+				Struct
+
+				A, B int
+			}) int {
+				return in.A + in.B
+			},
+			[]Arg{
+				Named("a", 12),
+				Named("b", 24),
+			},
+			nil,
+			[]interface{}{36},
+			"",
+		},
+
+		{
+			"replace named value",
+			func(in struct {
+				Struct
+
+				A, B int
+			}) int {
+				return in.A + in.B
+			},
+			[]Arg{
+				Named("a", 12),
+				Named("b", 24),
+			},
+			[]Arg{
+				Named("b", 30),
+			},
+			[]interface{}{42},
+			"",
+		},
+
+		{
+			"replace value through converter",
+			func(v int) int {
+				return v * 2
+			},
+			[]Arg{
+				Typed("12"),
+				Converter(func(v string) (int, error) { return strconv.Atoi(v) }),
+			},
+			[]Arg{
+				Typed("21"),
+			},
+			[]interface{}{42},
+			"",
+		},
+
+		{
+			"new input name",
+			func(in struct {
+				Struct
+
+				A int
+			}) int {
+				return in.A
+			},
+			[]Arg{
+				Named("a", 12),
+			},
+			[]Arg{
+				Named("b", 12),
+			},
+			nil,
+			ErrPlanMismatch.Error(),
+		},
+
+		{
+			"new input type",
+			func(v int) int {
+				return v
+			},
+			[]Arg{
+				Typed(12),
+			},
+			[]Arg{
+				Typed(int64(12)),
+			},
+			nil,
+			ErrPlanMismatch.Error(),
+		},
+
+		{
+			"new converter",
+			func(v int) int {
+				return v
+			},
+			[]Arg{
+				Typed(12),
+			},
+			[]Arg{
+				Converter(func(v string) (int, error) { return strconv.Atoi(v) }),
+			},
+			nil,
+			ErrPlanMismatch.Error(),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			require := require.New(t)
+
+			f, err := NewFunc(tt.Callback)
+			require.NoError(err)
+			p, err := f.Plan(tt.PlanArgs...)
+			require.NoError(err)
+
+			// Call multiple times to verify the plan is reusable.
+			for i := 0; i < 3; i++ {
+				result := p.Call(tt.CallArgs...)
+
+				// If we expect an error, check that
+				if tt.Err == "" {
+					require.NoError(result.Err())
+				} else {
+					require.Error(result.Err())
+					require.Contains(result.Err().Error(), tt.Err)
+				}
+
+				// Verify outputs
+				require.Equal(len(tt.Out), result.Len())
+				for i, out := range tt.Out {
+					require.Equal(out, result.Out(i))
+				}
+			}
+		})
+	}
+}
+
+func TestPlan_unsatisfied(t *testing.T) {
+	f, err := NewFunc(func(v int) int { return v })
+	require.NoError(t, err)
+
+	_, err = f.Plan(Typed("foo"))
+	require.Error(t, err)
+
+	var unsatisfied *ErrArgumentUnsatisfied
+	require.True(t, errors.As(err, &unsatisfied))
+}
+
+func TestPlan_mismatch(t *testing.T) {
+	f, err := NewFunc(func(v int) int { return v })
+	require.NoError(t, err)
+
+	p, err := f.Plan(Typed(12))
+	require.NoError(t, err)
+
+	result := p.Call(Typed("foo"))
+	require.True(t, errors.Is(result.Err(), ErrPlanMismatch))
+}
+
+func TestPlan_concurrent(t *testing.T) {
+	f, err := NewFunc(func(in struct {
+		Struct
+
+		A string
+		B int
+	}) string {
+		return in.A + strconv.Itoa(in.B)
+	})
+	require.NoError(t, err)
+
+	p, err := f.Plan(
+		Named("a", "value"),
+		Typed(int8(0)),
+		Converter(func(v int8) int { return int(v) }),
+	)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			result := p.Call(Typed(int8(i)))
+			require.NoError(t, result.Err())
+			require.Equal(t, "value"+strconv.Itoa(i), result.Out(0))
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+func TestPlanCallContext(t *testing.T) {
+	type ctxKey struct{}
+	contextType := reflect.TypeOf((*context.Context)(nil)).Elem()
+
+	t.Run("context argument", func(t *testing.T) {
+		require := require.New(t)
+
+		f := MustFunc(NewFunc(func(ctx context.Context, v int) string {
+			return ctx.Value(ctxKey{}).(string) + strconv.Itoa(v)
+		}))
+
+		p, err := f.Plan(
+			TypedAs(context.WithValue(context.Background(), ctxKey{}, "plan"), contextType),
+			Typed(1),
+		)
+		require.NoError(err)
+
+		ctx := context.WithValue(context.Background(), ctxKey{}, "call")
+		result := p.CallContext(ctx, Typed(2))
+		require.NoError(result.Err())
+		require.Equal("call2", result.Out(0))
+	})
+
+	t.Run("no context argument", func(t *testing.T) {
+		require := require.New(t)
+
+		f := MustFunc(NewFunc(func(v int) int { return v }))
+		p, err := f.Plan(Typed(1))
+		require.NoError(err)
+
+		result := p.CallContext(context.Background(), Typed(2))
+		require.NoError(result.Err())
+		require.Equal(2, result.Out(0))
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		require := require.New(t)
+
+		var called bool
+		f := MustFunc(NewFunc(func(v int) int {
+			called = true
+			return v
+		}))
+		p, err := f.Plan(Typed(1))
+		require.NoError(err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		result := p.CallContext(ctx)
+		require.False(called)
+
+		var canceled *ErrCallCanceled
+		require.ErrorAs(result.Err(), &canceled)
+		require.Equal(f, canceled.Func)
+		require.ErrorIs(result.Err(), context.Canceled)
+	})
+}
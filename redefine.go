@@ -4,6 +4,8 @@
 package argmapper
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -59,9 +61,12 @@ func (f *Func) Redefine(opts ...Arg) (*Func, error) {
 		hasErr = false
 	}
 
-	// Build our function type and implementation.
+	// Build our function type and implementation. The implementation
+	// takes the context of the call (if any) so that calling the redefined
+	// function with CallContext also uses the context for the original
+	// function call.
 	fnType := reflect.FuncOf([]reflect.Type{inputStruct}, out, false)
-	fn := reflect.MakeFunc(fnType, func(args []reflect.Value) []reflect.Value {
+	call := func(ctx context.Context, args []reflect.Value) []reflect.Value {
 		v := args[0]
 
 		// Get our value set. Our args are guaranteed to be a struct.
@@ -83,7 +88,12 @@ func (f *Func) Redefine(opts ...Arg) (*Func, error) {
 		}
 
 		// Call
-		result := f.Call(callArgs...)
+		var result Result
+		if ctx != nil {
+			result = f.CallContext(ctx, callArgs...)
+		} else {
+			result = f.Call(callArgs...)
+		}
 
 		// If we had an error, then we return the error. We always define
 		// our new functions to return a final error type so set that and
@@ -106,12 +116,32 @@ func (f *Func) Redefine(opts ...Arg) (*Func, error) {
 			out = append(result.out, reflect.Zero(errType))
 		}
 
+		// If our function returns a cleanup, then closing the result is
+		// the cleanup of the redefined function. Otherwise, there is no
+		// one to close the result so we run any cleanups now.
+		if f.cleanup != nil {
+			idx := len(out) - 1
+			out = append(out[:idx:idx],
+				cleanupValue(f.cleanup, result.Close), out[idx])
+		} else if err := result.Close(); err != nil {
+			out[len(out)-1] = reflect.ValueOf(err)
+		}
+
 		return out
+	}
+	fn := reflect.MakeFunc(fnType, func(args []reflect.Value) []reflect.Value {
+		return call(nil, args)
 	})
 
-	return NewFunc(fn.Interface(),
+	redefined, err := NewFunc(fn.Interface(),
 		FuncName(f.Name()), // Preserve the name from the original func
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	redefined.callCtx = call
+	return redefined, nil
 }
 
 // redefineInputs is called by Redefine to determine the input struct type
@@ -131,40 +161,19 @@ func (f *Func) redefineInputs(opts ...Arg) (reflect.Type, error) {
 	// Get our log we'll use for logging
 	log := builder.logger
 
-	// Get our call graph
-	g, vertexRoot, vertexF, vertexI, err := f.callGraph(builder)
-	if err != nil {
-		return nil, err
-	}
-
-	// We go through the call graph and modify the functions to be no-ops
-	// that just set the output values to zero values. This will let our
+	// Walk our call graph without calling anything. This will let our
 	// redefine process "call" each of our converters as if they work
 	// perfectly and then we can determine what inputs are required by
-	// just calling our target function and checking state.InputSet.
-	for _, v := range g.Vertices() {
-		switch v := v.(type) {
-		case *funcVertex:
-			// Copy the func since we're going to modify a field in it.
-			fCopy := *v.Func
-			v.Func = &fCopy
-
-			// Modify the function to be a zero producing function.
-			fCopy.fn = fCopy.zeroFunc()
-		}
-	}
-
-	// Build our call state and attempt to reach our target which is our
-	// function. This will recursively reach various conversion targets
-	// as necessary.
-	state := newCallState()
-	if _, err := f.reachTarget(log, &g, vertexRoot, vertexF, state, true); err != nil {
+	// checking state.InputSet.
+	run, err := f.dryRun(builder)
+	if err != nil {
 		return nil, err
 	}
+	state := run.State
 
 	// Determine our map of inputs
 	inputsProvided := map[interface{}]struct{}{}
-	for _, v := range vertexI {
+	for _, v := range run.Inputs {
 		inputsProvided[graph.VertexID(v)] = struct{}{}
 	}
 
@@ -242,8 +251,12 @@ func (f *Func) zeroFunc() reflect.Value {
 			v.Field(f.index).Set(reflect.Zero(f.Type))
 		}
 
-		// Get our result. If we're expecting an error value, return nil for that.
+		// Get our result. If we're expecting a cleanup or error value,
+		// return nil for those.
 		result := v.CallIn()
+		if f.cleanup != nil {
+			result = append(result, reflect.Zero(f.cleanup))
+		}
 		if len(result) < fn.NumOut() {
 			result = append(result, reflect.Zero(errType))
 		}
@@ -251,3 +264,238 @@ func (f *Func) zeroFunc() reflect.Value {
 		return result
 	})
 }
+
+// RedefineAs returns a new func whose underlying function has exactly the
+// function type fnType. This is useful when a function must have a specific
+// signature, such as to implement an interface method.
+//
+// Each parameter of fnType is given to this function as a typed value of
+// the parameter type, so the parameters must have distinct types. The
+// results of fnType (except a final error) are converted from the outputs
+// of this function. Only the converters in opts are used to convert the
+// outputs. Other values in opts are only given to this function, the same
+// as with Call.
+//
+// An error is returned if this function can't be called with the parameters
+// and opts or if the results can't be reached from its outputs. Both are
+// checked without calling anything.
+//
+// If fnType has a final error result, any error calling the function is
+// returned there. Otherwise, the function panics if the call fails, with an
+// error wrapping the error of the call as the panic value. If fnType has a
+// cleanup result (see Func), closing the result of the call is the cleanup.
+// Otherwise, the result is closed when the function returns.
+func (f *Func) RedefineAs(fnType reflect.Type, opts ...Arg) (*Func, error) {
+	if fnType == nil {
+		return nil, errors.New("RedefineAs requires a function type, got nil")
+	}
+	if fnType.Kind() != reflect.Func {
+		return nil, fmt.Errorf("RedefineAs requires a function type, got %s", fnType)
+	}
+	if fnType.IsVariadic() {
+		return nil, fmt.Errorf("RedefineAs doesn't support variadic function types, got %s", fnType)
+	}
+
+	// Each parameter is given as a typed value, so a parameter would
+	// replace any earlier parameter of the same type.
+	params := make([]reflect.Type, fnType.NumIn())
+	for i := range params {
+		params[i] = fnType.In(i)
+	}
+	if len(distinctTypes(params)) != len(params) {
+		return nil, fmt.Errorf(
+			"RedefineAs requires distinct parameter types, got %s", fnType)
+	}
+
+	// Determine the results that we convert to. These exclude the final
+	// error and cleanup results, which we set ourselves.
+	results := make([]reflect.Type, fnType.NumOut())
+	for i := range results {
+		results[i] = fnType.Out(i)
+	}
+	hasErr := len(results) > 0 && results[len(results)-1] == errType
+	if hasErr {
+		results = results[:len(results)-1]
+	}
+	var cleanup reflect.Type
+	if len(results) > 0 && isCleanup(results[len(results)-1]) {
+		cleanup = results[len(results)-1]
+		results = results[:len(results)-1]
+	}
+
+	// The outputs are only converted with the converters in opts.
+	builder, err := newArgBuilder(opts...)
+	if err != nil {
+		return nil, err
+	}
+	convOpts := []Arg{
+		Logger(builder.logger),
+		ConverterFunc(builder.convs...),
+		ConverterGen(builder.convGens...),
+	}
+
+	// Make sure that we can reach our function from the parameters and
+	// our results from the outputs of our function.
+	if err := f.redefineAsCheck(fnType, results, opts, convOpts); err != nil {
+		return nil, err
+	}
+
+	call := func(ctx context.Context, args []reflect.Value) []reflect.Value {
+		retval := make([]reflect.Value, fnType.NumOut())
+		for i := range retval {
+			retval[i] = reflect.Zero(fnType.Out(i))
+		}
+
+		// fail returns our error result or panics if we don't have one.
+		// The panic value wraps err so that it can still be inspected.
+		fail := func(err error) []reflect.Value {
+			if !hasErr {
+				panic(fmt.Errorf("call to function %q failed: %w", f.Name(), err))
+			}
+
+			retval[len(retval)-1] = reflect.ValueOf(err)
+			return retval
+		}
+
+		// Call our function with our parameters as typed values.
+		callArgs := make([]Arg, len(opts), len(opts)+len(args))
+		copy(callArgs, opts)
+		for i, arg := range args {
+			callArgs = append(callArgs, TypedAs(arg.Interface(), fnType.In(i)))
+		}
+
+		var result Result
+		if ctx != nil {
+			result = f.CallContext(ctx, callArgs...)
+		} else {
+			result = f.Call(callArgs...)
+		}
+		if err := result.Err(); err != nil {
+			return fail(err)
+		}
+
+		// Convert our outputs to our results. The conversion is closed
+		// before our result since it may depend on our outputs.
+		closeAll := result.Close
+		if len(results) > 0 {
+			out, closeConv, err := convertValues(results,
+				append(valueArgs(result.values()), convOpts...)...)
+			if err != nil {
+				_ = result.Close()
+				return fail(err)
+			}
+
+			copy(retval, out)
+			closeAll = func() error {
+				var err error
+				if cerr := closeConv(); cerr != nil {
+					err = multierror.Append(err, cerr)
+				}
+				if cerr := result.Close(); cerr != nil {
+					err = multierror.Append(err, cerr)
+				}
+
+				return err
+			}
+		}
+
+		// If we return a cleanup, then closing everything is the cleanup.
+		// Otherwise, there is no one to close the result so we do it now.
+		if cleanup != nil {
+			retval[len(results)] = cleanupValue(cleanup, closeAll)
+		} else if err := closeAll(); err != nil {
+			return fail(err)
+		}
+
+		return retval
+	}
+	fn := reflect.MakeFunc(fnType, func(args []reflect.Value) []reflect.Value {
+		return call(nil, args)
+	})
+
+	redefined, err := NewFunc(fn.Interface(),
+		FuncName(f.Name()), // Preserve the name from the original func
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	redefined.callCtx = call
+	return redefined, nil
+}
+
+// redefineAsCheck is called by RedefineAs to check that the function can be
+// called with the parameters of fnType and opts, and that the results can
+// be reached from the outputs using the converters in convOpts. This uses
+// zero values for the parameters and outputs.
+func (f *Func) redefineAsCheck(
+	fnType reflect.Type,
+	results []reflect.Type,
+	opts []Arg,
+	convOpts []Arg,
+) error {
+	callArgs := make([]Arg, len(opts), len(opts)+fnType.NumIn())
+	copy(callArgs, opts)
+	for i := 0; i < fnType.NumIn(); i++ {
+		param := reflect.Zero(fnType.In(i))
+		callArgs = append(callArgs, TypedAs(param.Interface(), fnType.In(i)))
+	}
+
+	builder, err := f.argBuilder(callArgs...)
+	if err != nil {
+		return err
+	}
+	if _, err := f.dryRun(builder); err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		return nil
+	}
+
+	conv, err := convertFunc(distinctTypes(results))
+	if err != nil {
+		return err
+	}
+
+	var outArgs []Arg
+	if !f.output.empty() {
+		outArgs = valueArgs(f.output.withValues(f.output.newStructValue()))
+	}
+
+	builder, err = conv.argBuilder(append(outArgs, convOpts...)...)
+	if err != nil {
+		return err
+	}
+	if _, err := conv.dryRun(builder); err != nil {
+		return fmt.Errorf("results of %s can't be reached from the outputs: %w", fnType, err)
+	}
+
+	return nil
+}
+
+// valueArgs returns the values of vs as args. Unlike ValueSet.Args, the
+// args have the exact types of the values, so nil values of interface types
+// are also given. This returns nil if vs is nil.
+func valueArgs(vs *ValueSet) []Arg {
+	if vs == nil {
+		return nil
+	}
+
+	args := make([]Arg, len(vs.values))
+	for i, v := range vs.values {
+		val := v.Value.Interface()
+		switch {
+		case v.Kind() == ValueTyped:
+			args[i] = TypedSubtypeAs(val, v.Type, v.Subtype)
+
+		case v.Subtype == "":
+			args[i] = NamedAs(v.Name, val, v.Type)
+
+		default:
+			args[i] = NamedSubtype(v.Name, val, v.Subtype)
+		}
+	}
+
+	return args
+}
@@ -4,8 +4,13 @@
 package argmapper
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/go-hclog"
@@ -62,6 +67,26 @@ func TestFuncRedefine(t *testing.T) {
 			[]interface{}{36},
 		},
 
+		{
+			"optional argument",
+			func(in struct {
+				Struct
+
+				A int
+				B int `argmapper:",optional"`
+			}) int {
+				return in.A + in.B
+			},
+			[]Arg{
+				Converter(func(v string) (int, error) { return strconv.Atoi(v) }),
+			},
+			"",
+			[]Arg{
+				Named("a", 12),
+			},
+			[]interface{}{12},
+		},
+
 		//-----------------------------------------------------------
 		// FILTER INPUT
 
@@ -255,3 +280,293 @@ func TestFuncRedefine(t *testing.T) {
 		})
 	}
 }
+
+func TestFuncRedefine_context(t *testing.T) {
+	require := require.New(t)
+
+	type ctxKey struct{}
+
+	f, err := NewFunc(func(in struct {
+		Struct
+
+		Ctx context.Context `argmapper:",typeOnly"`
+		A   int
+	}) string {
+		return in.Ctx.Value(ctxKey{}).(string) + strconv.Itoa(in.A)
+	})
+	require.NoError(err)
+
+	redefined, err := f.Redefine(Named("a", 42))
+	require.NoError(err)
+
+	// The redefined function should pass through the context to the
+	// original function even though the context is not an input.
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+	result := redefined.CallContext(ctx)
+	require.NoError(result.Err())
+	require.Equal("value42", result.Out(0))
+
+	// A canceled context should stop the original function call.
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	result = redefined.CallContext(canceled)
+	require.Error(result.Err())
+	require.True(errors.Is(result.Err(), context.Canceled))
+}
+
+func TestFuncRedefineAs(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Func     interface{}
+		Type     reflect.Type
+		Args     []Arg
+		Err      string
+		Params   []interface{}
+		Expected []interface{}
+	}{
+		{
+			"same types",
+			func(a int, b string) string {
+				return b + strconv.Itoa(a)
+			},
+			reflect.TypeOf(func(string, int) string { return "" }),
+			nil,
+			"",
+			[]interface{}{"a", 42},
+			[]interface{}{"a42"},
+		},
+
+		{
+			"converted param",
+			func(a int) string {
+				return strconv.Itoa(a * 2)
+			},
+			reflect.TypeOf(func(string) (string, error) { return "", nil }),
+			[]Arg{
+				Converter(func(v string) (int, error) { return strconv.Atoi(v) }),
+			},
+			"",
+			[]interface{}{"21"},
+			[]interface{}{"42", nil},
+		},
+
+		{
+			"converted results",
+			func(in struct {
+				Struct
+
+				A int
+			}) int {
+				return in.A * 2
+			},
+			reflect.TypeOf(func(int) (string, int, error) { return "", 0, nil }),
+			[]Arg{
+				Converter(func(v int) string { return strconv.Itoa(v) }),
+			},
+			"",
+			[]interface{}{21},
+			[]interface{}{"42", 42, nil},
+		},
+
+		{
+			"values in opts",
+			func(a int, b string) string {
+				return b + strconv.Itoa(a)
+			},
+			reflect.TypeOf(func(int) string { return "" }),
+			[]Arg{
+				Typed("value"),
+			},
+			"",
+			[]interface{}{42},
+			[]interface{}{"value42"},
+		},
+
+		{
+			"unsatisfied param",
+			func(a int) string {
+				return strconv.Itoa(a)
+			},
+			reflect.TypeOf(func(string) string { return "" }),
+			nil,
+			"could not be satisfied",
+			nil,
+			nil,
+		},
+
+		{
+			"unsatisfied result",
+			func(a int) int {
+				return a
+			},
+			reflect.TypeOf(func(int) string { return "" }),
+			nil,
+			"results of func(int) string can't be reached",
+			nil,
+			nil,
+		},
+
+		{
+			"results only use converters",
+			func(a int) int {
+				return a
+			},
+			reflect.TypeOf(func(int) string { return "" }),
+			[]Arg{
+				Typed("value"),
+			},
+			"results of func(int) string can't be reached",
+			nil,
+			nil,
+		},
+
+		{
+			"not a function",
+			func(a int) int {
+				return a
+			},
+			reflect.TypeOf(42),
+			nil,
+			"requires a function type",
+			nil,
+			nil,
+		},
+
+		{
+			"variadic",
+			func(a int) int {
+				return a
+			},
+			reflect.TypeOf(func(...int) int { return 0 }),
+			nil,
+			"doesn't support variadic",
+			nil,
+			nil,
+		},
+
+		{
+			"duplicate parameter types",
+			func(a int) int {
+				return a
+			},
+			reflect.TypeOf(func(int, int) int { return 0 }),
+			nil,
+			"requires distinct parameter types",
+			nil,
+			nil,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			require := require.New(t)
+
+			f, err := NewFunc(tt.Func)
+			require.NoError(err)
+
+			redefined, err := f.RedefineAs(tt.Type, tt.Args...)
+			if tt.Err != "" {
+				require.Error(err)
+				require.Contains(err.Error(), tt.Err)
+				return
+			}
+			require.NoError(err)
+			require.Equal(tt.Type, reflect.TypeOf(redefined.Func()))
+
+			// Call the function directly.
+			in := make([]reflect.Value, len(tt.Params))
+			for i, p := range tt.Params {
+				in[i] = reflect.ValueOf(p)
+			}
+			out := reflect.ValueOf(redefined.Func()).Call(in)
+			require.Len(out, len(tt.Expected))
+			for i, expected := range tt.Expected {
+				require.Equal(expected, out[i].Interface())
+			}
+		})
+	}
+}
+
+func TestFuncRedefineAs_error(t *testing.T) {
+	require := require.New(t)
+
+	failed := errors.New("failed")
+	f, err := NewFunc(func(a int) (int, error) { return 0, failed })
+	require.NoError(err)
+
+	redefined, err := f.RedefineAs(reflect.TypeOf(func(int) (int, error) { return 0, nil }))
+	require.NoError(err)
+	fn := redefined.Func().(func(int) (int, error))
+	_, err = fn(42)
+	require.ErrorIs(err, failed)
+
+	// Without an error result, the function panics with the error.
+	redefined, err = f.RedefineAs(reflect.TypeOf(func(int) int { return 0 }))
+	require.NoError(err)
+	var recovered interface{}
+	func() {
+		defer func() { recovered = recover() }()
+		redefined.Func().(func(int) int)(42)
+	}()
+	panicErr, ok := recovered.(error)
+	require.True(ok)
+	require.ErrorIs(panicErr, failed)
+}
+
+func TestFuncRedefineAs_nilType(t *testing.T) {
+	f, err := NewFunc(func(a int) int { return a })
+	require.NoError(t, err)
+
+	_, err = f.RedefineAs(nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "got nil")
+}
+
+func TestFuncRedefineAs_interface(t *testing.T) {
+	require := require.New(t)
+
+	f, err := NewFunc(func(r io.Reader) (string, error) {
+		data, err := io.ReadAll(r)
+		return string(data), err
+	})
+	require.NoError(err)
+
+	redefined, err := f.RedefineAs(reflect.TypeOf(func(io.Reader) (fmt.Stringer, error) { return nil, nil }),
+		Converter(func(v string) fmt.Stringer { return testStringer(v) }),
+	)
+	require.NoError(err)
+
+	fn := redefined.Func().(func(io.Reader) (fmt.Stringer, error))
+	out, err := fn(strings.NewReader("hello"))
+	require.NoError(err)
+	require.Equal("hello", out.String())
+}
+
+func TestFuncRedefineAs_context(t *testing.T) {
+	require := require.New(t)
+
+	type ctxKey struct{}
+
+	f, err := NewFunc(func(ctx context.Context, a int) string {
+		return ctx.Value(ctxKey{}).(string) + strconv.Itoa(a)
+	})
+	require.NoError(err)
+
+	redefined, err := f.RedefineAs(reflect.TypeOf(func(context.Context, int) string { return "" }))
+	require.NoError(err)
+
+	// The context parameter is given to the original function.
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+	fn := redefined.Func().(func(context.Context, int) string)
+	require.Equal("value42", fn(ctx, 42))
+
+	// Calling the redefined function also uses the context of the call.
+	result := redefined.CallContext(ctx, Typed(42))
+	require.NoError(result.Err())
+	require.Equal("value42", result.Out(0))
+}
+
+// testStringer is a fmt.Stringer for tests.
+type testStringer string
+
+func (s testStringer) String() string { return string(s) }
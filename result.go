@@ -3,16 +3,30 @@
 
 package argmapper
 
-import "reflect"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
 
 // Result is returned from a Call with the results of the function call.
 //
 // This structure lets you access multiple results values. If the function
 // call had a final return value type "error", this is treated specially
-// and is present via the Err call and not via Out.
+// and is present via the Err call and not via Out. Likewise, a returned
+// cleanup function is not present via Out and is run by Close.
 type Result struct {
 	out      []reflect.Value
 	buildErr error
+
+	// output is the output ValueSet of the called function. This is nil
+	// if the function wasn't called.
+	output *ValueSet
+
+	// cleanups are the cleanup functions of the call. This is a pointer so
+	// that copies of the result share them. See Close.
+	cleanups *cleanupStack
 }
 
 // resultError returns a Result with an error.
@@ -20,6 +34,17 @@ func resultError(err error) Result {
 	return Result{buildErr: err}
 }
 
+// ErrorResult returns a Result for a call that failed with err. This can
+// be used by an InterceptorFunc to fail a call without invoking the function.
+// err must not be nil, otherwise the result fails with an error saying so.
+func ErrorResult(err error) Result {
+	if err == nil {
+		err = errors.New("ErrorResult requires a non-nil error")
+	}
+
+	return resultError(err)
+}
+
 // Err returns any error that occurred as part of the call. This can
 // be an error in the process of calling or it can be an error from the
 // result of the call.
@@ -51,6 +76,117 @@ func (r *Result) Out(i int) interface{} {
 	return r.out[i].Interface()
 }
 
+// OutValue is the same as Out but returns the reflect.Value of the result.
+func (r *Result) OutValue(i int) reflect.Value {
+	return r.out[i]
+}
+
+// Typed returns the typed output value of the given type, or nil if there
+// is no such value. Outputs of a function that returns a struct embedding
+// Struct are resolved using the fields of the struct.
+func (r *Result) Typed(t reflect.Type) *Value {
+	vs := r.values()
+	if vs == nil {
+		return nil
+	}
+
+	return vs.Typed(t)
+}
+
+// Named returns the named output value with the given name, or nil if
+// there is no such value. Named outputs are only possible if the function
+// returns a struct embedding Struct. Names are case insensitive.
+func (r *Result) Named(n string) *Value {
+	vs := r.values()
+	if vs == nil {
+		return nil
+	}
+
+	return vs.Named(strings.ToLower(n))
+}
+
+// Args returns all of the output values as a slice of Arg so they can be
+// given directly to another call. This returns nil if the call failed.
+func (r *Result) Args() []Arg {
+	vs := r.values()
+	if vs == nil {
+		return nil
+	}
+
+	return vs.Args()
+}
+
+// Into copies the output values into the struct pointed to by v. The fields
+// of the struct are matched the same way as the arguments of a function
+// taking a struct embedding Struct, though the struct doesn't need to embed
+// Struct: named fields are set from the output value with the same name
+// and, if there is none, from the typed output value of the same type.
+// Fields that don't match any output value are left unchanged.
+//
+// If the call failed, this returns the error of the call.
+func (r *Result) Into(v interface{}) error {
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("result can only be copied into a pointer to a struct, got %T", v)
+	}
+
+	target, err := newValueSetFromStruct(rv.Elem().Type())
+	if err != nil {
+		return err
+	}
+
+	vs := r.values()
+	if vs == nil {
+		return nil
+	}
+
+	structVal := rv.Elem()
+	for _, tv := range target.values {
+		if tv.collected() {
+			continue
+		}
+
+		var src *Value
+		if tv.Kind() == ValueNamed {
+			src = vs.Named(tv.Name)
+		}
+		if src == nil {
+			src = vs.TypedSubtype(tv.Type, tv.Subtype)
+		}
+		if src == nil {
+			src = vs.Typed(tv.Type)
+		}
+		if src == nil || !src.Value.Type().AssignableTo(tv.Type) {
+			continue
+		}
+
+		structVal.Field(tv.index).Set(tv.fieldValue(src.Value))
+	}
+
+	return nil
+}
+
+// Close runs the cleanup functions returned by the converters and the
+// target function of the call, in the reverse order they were returned.
+// This should be called once the outputs of the call are no longer used.
+// See the Func docs on cleanup functions.
+//
+// Every cleanup is run even if an earlier cleanup fails, and the errors of
+// all the failed cleanups are returned. Cleanups are only run once, so
+// calling Close again does nothing. If the call failed, the cleanups were
+// already run and Close does nothing.
+func (r *Result) Close() error {
+	if r.cleanups == nil {
+		return nil
+	}
+
+	return r.cleanups.run()
+}
+
 // Len returns the number of outputs, excluding any final error output.
 //
 // Len does not include the "error" type if it was the final output type.
@@ -73,3 +209,20 @@ func (r *Result) hasError() bool {
 	final := r.out[len(r.out)-1]
 	return final.Type() == errType
 }
+
+// values returns the output ValueSet of the called function with the
+// values set from this result. This returns nil if the function wasn't
+// called or the call failed.
+func (r *Result) values() *ValueSet {
+	if r.output == nil || r.Err() != nil {
+		return nil
+	}
+
+	// If there are no outputs, there are no values to set.
+	if r.output.empty() {
+		return r.output
+	}
+
+	out := r.output.result(*r).out[0]
+	return r.output.withValues(&structValue{typ: r.output, value: out})
+}
@@ -0,0 +1,171 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testResultOutput struct {
+	Struct
+
+	A string
+	B int `argmapper:",typeOnly"`
+}
+
+func TestResult_values(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Callback interface{}
+		Named    map[string]interface{}
+		Typed    map[reflect.Type]interface{}
+	}{
+		{
+			"lifted",
+			func() (string, int, error) {
+				return "foo", 42, nil
+			},
+			nil,
+			map[reflect.Type]interface{}{
+				reflect.TypeOf(""): "foo",
+				reflect.TypeOf(0):  42,
+			},
+		},
+
+		{
+			"struct",
+			func() testResultOutput {
+				return testResultOutput{A: "foo", B: 42}
+			},
+			map[string]interface{}{
+				"A": "foo",
+			},
+			map[reflect.Type]interface{}{
+				reflect.TypeOf(0): 42,
+			},
+		},
+
+		{
+			"struct pointer",
+			func() *testResultOutput {
+				return &testResultOutput{A: "foo", B: 42}
+			},
+			map[string]interface{}{
+				"a": "foo",
+			},
+			map[reflect.Type]interface{}{
+				reflect.TypeOf(0): 42,
+			},
+		},
+
+		{
+			"no outputs",
+			func() {},
+			nil,
+			nil,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			require := require.New(t)
+
+			f, err := NewFunc(tt.Callback)
+			require.NoError(err)
+			result := f.Call()
+			require.NoError(result.Err())
+
+			for n, expected := range tt.Named {
+				v := result.Named(n)
+				require.NotNil(v)
+				require.Equal(expected, v.Value.Interface())
+			}
+			for typ, expected := range tt.Typed {
+				v := result.Typed(typ)
+				require.NotNil(v)
+				require.Equal(expected, v.Value.Interface())
+			}
+
+			require.Nil(result.Named("missing"))
+			require.Nil(result.Typed(reflect.TypeOf(1.5)))
+			require.Len(result.Args(), len(tt.Named)+len(tt.Typed))
+		})
+	}
+}
+
+func TestResult_error(t *testing.T) {
+	require := require.New(t)
+
+	f, err := NewFunc(func() (string, error) { return "", errors.New("failed") })
+	require.NoError(err)
+	result := f.Call()
+	require.Error(result.Err())
+	require.Nil(result.Typed(reflect.TypeOf("")))
+	require.Nil(result.Args())
+
+	var out struct{ V string }
+	require.EqualError(result.Into(&out), "failed")
+}
+
+func TestResultOutValue(t *testing.T) {
+	f, err := NewFunc(func() (string, error) { return "foo", nil })
+	require.NoError(t, err)
+	result := f.Call()
+	require.Equal(t, reflect.TypeOf(""), result.OutValue(0).Type())
+	require.Equal(t, "foo", result.OutValue(0).Interface())
+}
+
+func TestResultInto(t *testing.T) {
+	require := require.New(t)
+
+	f, err := NewFunc(func() testResultOutput {
+		return testResultOutput{A: "foo", B: 42}
+	})
+	require.NoError(err)
+	result := f.Call()
+
+	var out struct {
+		Name  string `argmapper:"a"`
+		Count int
+		Other float64
+		Opt   Optional[int] `argmapper:",typeOnly"`
+	}
+	out.Other = 1.5
+	require.NoError(result.Into(&out))
+	require.Equal("foo", out.Name)
+	require.Equal(42, out.Count)
+	require.Equal(1.5, out.Other)
+	require.Equal(Optional[int]{Value: 42, Valid: true}, out.Opt)
+
+	require.Error(result.Into(out))
+}
+
+func TestResultArgs(t *testing.T) {
+	require := require.New(t)
+
+	f, err := NewFunc(func() testResultOutput {
+		return testResultOutput{A: "foo", B: 42}
+	})
+	require.NoError(err)
+
+	g, err := NewFunc(func(in struct {
+		Struct
+
+		A string
+		V int
+	}) string {
+		return in.A + fmt.Sprint(in.V)
+	})
+	require.NoError(err)
+
+	fResult := f.Call()
+	result := g.Call(fResult.Args()...)
+	require.NoError(result.Err())
+	require.Equal("foo42", result.Out(0))
+}
@@ -52,6 +52,88 @@ import "reflect"
 //
 // Note the comma before the "typeOnly" string. The comma is necessary
 // so tell argmapper you're setting an option versus renaming a field.
+//
+// Collected Parameters
+//
+// A slice field can be marked to collect every available value assignable
+// to the element type of the slice using the "collect" option. The name of
+// the field is ignored. The field below is populated with every direct input
+// and every converter output that implements io.Closer, including values of
+// type []io.Closer, which are flattened.
+//
+//   type MyParams {
+//     argmapper.Struct
+//
+//     Closers []io.Closer `argmapper:",collect"`
+//   }
+//
+// The values are always in the same order: direct inputs sorted by name,
+// type, and subtype, followed by converter outputs sorted by converter name.
+// Converters that can't be called with the available inputs are skipped.
+// A collected field is always satisfied, even if no values are available.
+//
+// A field or function parameter of type Collect[T] always collects values.
+// Variadic function parameters also collect values.
+//
+// Named Maps
+//
+// A map field with string keys can be marked to collect every available
+// named value assignable to the element type of the map using the
+// "namedMap" option. The name of the field is ignored. The field below is
+// populated with every named input and every named converter output of
+// type Handler, keyed by name. Typed values are never included.
+//
+//   type MyParams {
+//     argmapper.Struct
+//
+//     Handlers map[string]Handler `argmapper:",namedMap"`
+//   }
+//
+// If a named input and a named converter output share a name, the converter
+// output is used. Like collected fields, a named map is always satisfied.
+//
+// Optional Parameters
+//
+// A field can be marked as optional using the "optional" option. If no
+// input or converter can produce the value, the field is set to its zero
+// value rather than the call failing. Converters are still used if they
+// can produce the value.
+//
+//   type MyParams {
+//     argmapper.Struct
+//
+//     Timeout time.Duration `argmapper:",optional"`
+//   }
+//
+// A field or function parameter of type Optional[T] is always optional and
+// is matched as a T. Its Valid field reports whether the value was set.
+//
+// Optional parameters are never required by a function returned by
+// Func.Redefine.
+//
+// Default Values
+//
+// A field can be given a default value using the "default" option. The
+// default is used if no input or converter can produce the value, so the
+// field is also optional.
+//
+//   type MyParams {
+//     argmapper.Struct
+//
+//     Timeout time.Duration `argmapper:"timeout,default=30s"`
+//   }
+//
+// Defaults are parsed when the Func is created. They are supported for
+// any type implementing encoding.TextUnmarshaler, time.Duration, strings,
+// bools, and numbers. Since options are separated by commas, a default value
+// can't contain a comma. Func.Explain reports arguments that use their
+// default with MatchDefault.
+//
+// Lazy Parameters
+//
+// A field or function parameter of type Lazy[T] is matched as a T, but the
+// converters that produce the value are only called when Lazy.Get is
+// called. Lazy parameters are still required and can't be optional.
 type Struct struct {
 	structInterface
 }
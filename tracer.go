@@ -0,0 +1,187 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// The names of the spans started by a call. See SpanTracer.
+const (
+	// SpanCall covers an entire call, such as Func.Call or Convert.
+	SpanCall = "argmapper.call"
+
+	// SpanGraph covers building the graph for a call.
+	SpanGraph = "argmapper.graph"
+
+	// SpanPaths covers choosing the paths to reach the arguments of a
+	// function, either the target function or a converter.
+	SpanPaths = "argmapper.paths"
+
+	// SpanConverter covers the invocation of a converter.
+	SpanConverter = "argmapper.converter"
+
+	// SpanTarget covers the invocation of the target function.
+	SpanTarget = "argmapper.target"
+)
+
+// SpanTracer starts spans for the steps of a call. This can be used to
+// integrate with a tracing system such as OpenTelemetry. Set the tracer
+// for a call with the Tracer Arg.
+//
+// Attributes are given as alternating keys and values, the same as
+// hclog. Keys are always strings. Spans are started with one of the Span*
+// names, and the spans of a call are started with the context returned
+// when starting the SpanCall span.
+//
+// A SpanTracer must be safe for concurrent use if it is used with Parallel.
+type SpanTracer interface {
+	// Start starts a span with the given name and attributes. The
+	// returned context is used to start any child spans.
+	Start(ctx context.Context, name string, attrs ...interface{}) (context.Context, Span)
+}
+
+// Span is a single span started by a SpanTracer.
+type Span interface {
+	// End ends the span. err is the error of the operation, if it failed.
+	// Additional attributes may be given.
+	End(err error, attrs ...interface{})
+}
+
+// Tracer sets the SpanTracer to use to trace a call.
+func Tracer(t SpanTracer) Arg {
+	return func(a *argBuilder) error {
+		a.tracer = t
+		return nil
+	}
+}
+
+// startSpan starts a span with t. If t is nil, this returns a span that
+// does nothing. If ctx is nil, the background context is used.
+func startSpan(t SpanTracer, ctx context.Context, name string, attrs ...interface{}) (context.Context, Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if t == nil {
+		return ctx, noopSpan{}
+	}
+
+	return t.Start(ctx, name, attrs...)
+}
+
+// valueStrings returns the descriptions of the values in vs for
+// span attributes.
+func valueStrings(vs *ValueSet) []string {
+	if vs == nil {
+		return nil
+	}
+
+	result := make([]string, len(vs.values))
+	for i, v := range vs.values {
+		result[i] = v.String()
+	}
+
+	return result
+}
+
+// noopSpan is the Span used when there is no SpanTracer.
+type noopSpan struct{}
+
+func (noopSpan) End(error, ...interface{}) {}
+
+// RecordingTracer is a SpanTracer that records all spans in memory. This is
+// meant for tests. The zero value is ready to use.
+type RecordingTracer struct {
+	lock  sync.Mutex
+	spans []*RecordedSpan
+}
+
+// RecordedSpan is a span recorded by a RecordingTracer. The fields must not
+// be accessed until the span has ended.
+type RecordedSpan struct {
+	// Name is the name of the span.
+	Name string
+
+	// Parent is the parent span, if the span was started with the
+	// context of another span of the same tracer.
+	Parent *RecordedSpan
+
+	// Attrs are the attributes given when starting and ending the span.
+	Attrs map[string]interface{}
+
+	// Err is the error given when ending the span.
+	Err error
+
+	// StartTime and EndTime are the times the span started and ended.
+	// EndTime is zero if the span hasn't ended.
+	StartTime, EndTime time.Time
+
+	tracer *RecordingTracer
+}
+
+// recordedSpanKey is the context key for the current RecordedSpan.
+type recordedSpanKey struct{}
+
+// Start implements SpanTracer.
+func (t *RecordingTracer) Start(ctx context.Context, name string, attrs ...interface{}) (context.Context, Span) {
+	span := &RecordedSpan{
+		Name:      name,
+		Attrs:     map[string]interface{}{},
+		StartTime: time.Now(),
+		tracer:    t,
+	}
+	if parent, ok := ctx.Value(recordedSpanKey{}).(*RecordedSpan); ok && parent.tracer == t {
+		span.Parent = parent
+	}
+	setAttrs(span.Attrs, attrs)
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, recordedSpanKey{}, span), span
+}
+
+// Spans returns all the recorded spans in the order they were started.
+func (t *RecordingTracer) Spans() []*RecordedSpan {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	result := make([]*RecordedSpan, len(t.spans))
+	copy(result, t.spans)
+	return result
+}
+
+// Reset removes all the recorded spans.
+func (t *RecordingTracer) Reset() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.spans = nil
+}
+
+// End implements Span.
+func (s *RecordedSpan) End(err error, attrs ...interface{}) {
+	s.tracer.lock.Lock()
+	defer s.tracer.lock.Unlock()
+
+	s.Err = err
+	s.EndTime = time.Now()
+	setAttrs(s.Attrs, attrs)
+}
+
+// setAttrs sets the alternating keys and values of attrs in m. A key
+// without a value is ignored.
+func setAttrs(m map[string]interface{}, attrs []interface{}) {
+	for i := 0; i+1 < len(attrs); i += 2 {
+		m[fmt.Sprint(attrs[i])] = attrs[i+1]
+	}
+}
+
+var (
+	_ SpanTracer = (*RecordingTracer)(nil)
+	_ Span       = (*RecordedSpan)(nil)
+)
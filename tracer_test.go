@@ -0,0 +1,198 @@
+// Copyright IBM Corp. 2020, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package argmapper
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracer(t *testing.T) {
+	require := require.New(t)
+
+	conv := MustFunc(NewFunc(func(v string) (int, error) {
+		return strconv.Atoi(v)
+	}, FuncName("atoi")))
+	f := MustFunc(NewFunc(func(v int) int { return v * 2 }, FuncName("double")))
+
+	var tracer RecordingTracer
+	result := f.Call(Typed("21"), ConverterFunc(conv), Tracer(&tracer))
+	require.NoError(result.Err())
+	require.Equal(42, result.Out(0))
+
+	var names []string
+	spans := map[string]*RecordedSpan{}
+	for _, span := range tracer.Spans() {
+		names = append(names, span.Name)
+		require.NoError(span.Err)
+		require.False(span.EndTime.IsZero())
+		if _, ok := spans[span.Name]; !ok {
+			spans[span.Name] = span
+		}
+	}
+	require.Equal([]string{
+		SpanCall,
+		SpanGraph,
+		SpanPaths,
+		SpanConverter,
+		SpanTarget,
+	}, names)
+
+	call := spans[SpanCall]
+	require.Nil(call.Parent)
+	require.Equal("double", call.Attrs["func"])
+	for _, name := range names[1:] {
+		require.Same(call, spans[name].Parent, name)
+	}
+
+	require.Equal("double", spans[SpanPaths].Attrs["func"])
+	require.Equal(1, spans[SpanPaths].Attrs["requirements"])
+	require.Equal("atoi", spans[SpanConverter].Attrs["func"])
+	require.Equal([]string{"type: string"}, spans[SpanConverter].Attrs["inputs"])
+	require.Equal([]string{"type: int"}, spans[SpanConverter].Attrs["outputs"])
+	require.Equal("double", spans[SpanTarget].Attrs["func"])
+	require.Equal([]string{"type: int"}, spans[SpanTarget].Attrs["inputs"])
+}
+
+func TestTracer_error(t *testing.T) {
+	require := require.New(t)
+
+	failed := errors.New("failed")
+	f := MustFunc(NewFunc(func(v int) int { return v }))
+
+	var tracer RecordingTracer
+	result := f.Call(
+		Typed("42"),
+		Converter(func(v string) (int, error) { return 0, failed }),
+		Tracer(&tracer),
+	)
+	require.ErrorIs(result.Err(), failed)
+
+	spans := tracer.Spans()
+	require.Equal(SpanCall, spans[0].Name)
+	require.ErrorIs(spans[0].Err, failed)
+
+	last := spans[len(spans)-1]
+	require.Equal(SpanConverter, last.Name)
+	require.ErrorIs(last.Err, failed)
+	require.NotContains(last.Attrs, "outputs")
+}
+
+func TestTracer_unsatisfied(t *testing.T) {
+	require := require.New(t)
+
+	f := MustFunc(NewFunc(func(v int) int { return v }))
+
+	var tracer RecordingTracer
+	result := f.Call(Tracer(&tracer))
+	require.Error(result.Err())
+
+	spans := tracer.Spans()
+	require.Len(spans, 2)
+	require.Equal(SpanGraph, spans[1].Name)
+	require.Error(spans[1].Err)
+	require.Error(spans[0].Err)
+}
+
+func TestTracer_context(t *testing.T) {
+	require := require.New(t)
+
+	var tracer RecordingTracer
+	ctx, parent := tracer.Start(context.Background(), "parent")
+
+	f := MustFunc(NewFunc(func(v int) int { return v }))
+	result := f.CallContext(ctx, Typed(42), Tracer(&tracer))
+	require.NoError(result.Err())
+	parent.End(nil)
+
+	spans := tracer.Spans()
+	require.Equal(SpanCall, spans[1].Name)
+	require.Same(spans[0], spans[1].Parent)
+}
+
+func TestTracer_plan(t *testing.T) {
+	require := require.New(t)
+
+	f := MustFunc(NewFunc(func(v int) int { return v }))
+
+	var tracer RecordingTracer
+	p, err := f.Plan(Typed(1), Tracer(&tracer))
+	require.NoError(err)
+	require.Empty(tracer.Spans())
+
+	result := p.Call(Typed(2))
+	require.NoError(result.Err())
+
+	var names []string
+	for _, span := range tracer.Spans() {
+		names = append(names, span.Name)
+	}
+	require.Equal([]string{SpanCall, SpanPaths, SpanTarget}, names)
+
+	// A tracer given to the call replaces the tracer of the plan.
+	var other RecordingTracer
+	tracer.Reset()
+	result = p.Call(Tracer(&other))
+	require.NoError(result.Err())
+	require.Empty(tracer.Spans())
+	require.Len(other.Spans(), 3)
+}
+
+func TestTracer_dryRun(t *testing.T) {
+	require := require.New(t)
+
+	f := MustFunc(NewFunc(func(v int) int { return v }))
+
+	var tracer RecordingTracer
+	args := []Arg{
+		Typed("42"),
+		Converter(func(v string) (int, error) { return strconv.Atoi(v) }),
+		Tracer(&tracer),
+	}
+
+	_, err := f.Explain(args...)
+	require.NoError(err)
+	_, err = f.Redefine(args...)
+	require.NoError(err)
+	require.Empty(tracer.Spans())
+}
+
+func TestTracer_parallel(t *testing.T) {
+	require := require.New(t)
+
+	f := MustFunc(NewFunc(func(a int, b string) string {
+		return strconv.Itoa(a) + b
+	}))
+
+	var tracer RecordingTracer
+	result := f.Call(
+		Typed(int32(4)),
+		Typed(2.0),
+		Converter(func(v int32) int {
+			time.Sleep(10 * time.Millisecond)
+			return int(v)
+		}),
+		Converter(func(v float64) string {
+			time.Sleep(10 * time.Millisecond)
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}),
+		Parallel(2),
+		Tracer(&tracer),
+	)
+	require.NoError(result.Err())
+	require.Equal("42", result.Out(0))
+
+	var converters int
+	for _, span := range tracer.Spans() {
+		if span.Name == SpanConverter {
+			converters++
+		}
+	}
+	require.Equal(2, converters)
+}
@@ -88,6 +88,35 @@ const (
 type valueInternal struct {
 	// index is the struct field index for the ValueSet on which to set values.
 	index int
+
+	// collect is true if this value is a slice that collects every
+	// available value assignable to its element type rather than being
+	// matched directly. See Struct and Collect.
+	collect bool
+
+	// namedMap is true if this value is a map keyed by name that collects
+	// every available named value assignable to its element type.
+	namedMap bool
+
+	// optional is true if the value is set to its zero value when it
+	// can't be satisfied rather than failing the call. If optionalType is
+	// set, the struct field is of that Optional type rather than Type.
+	optional     bool
+	optionalType reflect.Type
+
+	// defaultValue, if valid, is the value used for an optional value
+	// that can't be satisfied. This is set with the "default" option.
+	defaultValue reflect.Value
+
+	// lazyType, if set, is the Lazy type of the struct field. The value
+	// is only reached when it is first needed. See Lazy.
+	lazyType reflect.Type
+}
+
+// collected returns true if this value collects other values rather than
+// being matched directly.
+func (v *valueInternal) collected() bool {
+	return v.collect || v.namedMap
 }
 
 // NewValueSet creates a ValueSet from a list of expected values.
@@ -247,13 +276,78 @@ func newValueSetFromStruct(typ reflect.Type) (*ValueSet, error) {
 			name = ""
 		}
 
+		// Determine if we're collecting values
+		_, collect := options["collect"]
+		if isCollect(sf.Type) {
+			collect = true
+		}
+		if collect && sf.Type.Kind() != reflect.Slice {
+			return nil, fmt.Errorf(
+				"field %s: collect requires a slice type, got %s", sf.Name, sf.Type)
+		}
+		_, namedMap := options["namedMap"]
+		if namedMap && (sf.Type.Kind() != reflect.Map || sf.Type.Key().Kind() != reflect.String) {
+			return nil, fmt.Errorf(
+				"field %s: namedMap requires a map with string keys, got %s", sf.Name, sf.Type)
+		}
+
+		// Determine if the value is optional. Optional types are
+		// matched using the type of the value they wrap.
+		valueType := sf.Type
+		_, optional := options["optional"]
+		var optionalType reflect.Type
+		if isOptionalPtr(valueType) {
+			return nil, fmt.Errorf(
+				"field %s: Optional can't be used as a pointer, use %s", sf.Name, valueType.Elem())
+		}
+		if isOptional(valueType) {
+			optional = true
+			optionalType = valueType
+			valueType = valueType.Field(0).Type
+		}
+
+		// A default value makes the value optional.
+		var defaultValue reflect.Value
+		if raw, ok := options["default"]; ok {
+			var err error
+			defaultValue, err = parseDefault(valueType, raw)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: invalid default value: %w", sf.Name, err)
+			}
+
+			optional = true
+		}
+
+		// Lazy types are also matched using the type of the value they
+		// wrap. A lazy value is reached later, so it can't be optional.
+		var lazyType reflect.Type
+		if isLazyPtr(valueType) {
+			return nil, fmt.Errorf(
+				"field %s: Lazy can't be used as a pointer, use %s", sf.Name, valueType.Elem())
+		}
+		if isLazy(valueType) {
+			if optional {
+				return nil, fmt.Errorf(
+					"field %s: Lazy values can't be optional", sf.Name)
+			}
+
+			lazyType = valueType
+			valueType = lazyElem(valueType)
+		}
+
 		// Record it
 		value := Value{
 			Name:    name,
-			Type:    sf.Type,
+			Type:    valueType,
 			Subtype: options["subtype"],
 			valueInternal: valueInternal{
-				index: i,
+				index:        i,
+				collect:      collect,
+				namedMap:     namedMap,
+				optional:     optional,
+				optionalType: optionalType,
+				defaultValue: defaultValue,
+				lazyType:     lazyType,
 			},
 		}
 
@@ -341,7 +435,7 @@ func (vs *ValueSet) Signature() []reflect.Type {
 
 	result := make([]reflect.Type, len(vs.typedValues))
 	for _, v := range vs.typedValues {
-		result[v.index] = v.Type
+		result[v.index] = v.fieldType()
 	}
 
 	return result
@@ -360,7 +454,7 @@ func (vs *ValueSet) SignatureValues() []reflect.Value {
 	if vs.lifted() {
 		result := make([]reflect.Value, len(vs.typedValues))
 		for _, v := range vs.typedValues {
-			result[v.index] = v.valueOrZero()
+			result[v.index] = v.fieldValue(v.Value)
 		}
 
 		return result
@@ -369,7 +463,7 @@ func (vs *ValueSet) SignatureValues() []reflect.Value {
 	// Not lifted, meaning we return a struct
 	structOut := reflect.New(vs.structType).Elem()
 	for _, f := range vs.values {
-		structOut.Field(f.index).Set(f.valueOrZero())
+		structOut.Field(f.index).Set(f.fieldValue(f.Value))
 	}
 
 	return []reflect.Value{structOut}
@@ -394,7 +488,7 @@ func (vs *ValueSet) FromSignature(values []reflect.Value) error {
 	// Get our first result which should be our struct
 	structVal := values[0]
 	for i, v := range vs.values {
-		vs.values[i].Value = structVal.Field(v.index)
+		vs.values[i].Value = v.fromField(structVal.Field(v.index))
 	}
 
 	return nil
@@ -410,6 +504,36 @@ func (vs *ValueSet) FromResult(r Result) error {
 	return vs.FromSignature(r.out)
 }
 
+// withValues returns a copy of this ValueSet with the Value field of every
+// value set from the given struct value. The struct value must have been
+// created by newStructValue on this ValueSet.
+func (vs *ValueSet) withValues(sv *structValue) *ValueSet {
+	result := &ValueSet{
+		structType:     vs.structType,
+		structPointers: vs.structPointers,
+		values:         make([]*Value, len(vs.values)),
+		namedValues:    map[string]*Value{},
+		typedValues:    map[reflect.Type]*Value{},
+		isLifted:       vs.isLifted,
+	}
+
+	for i, v := range vs.values {
+		value := *v
+		value.Value = v.fromField(sv.Field(v.index))
+
+		result.values[i] = &value
+		switch value.Kind() {
+		case ValueNamed:
+			result.namedValues[value.Name] = &value
+
+		case ValueTyped:
+			result.typedValues[value.Type] = &value
+		}
+	}
+
+	return result
+}
+
 // New returns a new structValue that can be used for value population.
 func (t *ValueSet) newStructValue() *structValue {
 	result := &structValue{typ: t}
@@ -429,6 +553,17 @@ func (t *ValueSet) empty() bool {
 	return t.structType == nil || len(t.values) == 0
 }
 
+// optional returns true if every value in this set is optional.
+func (t *ValueSet) optional() bool {
+	for _, v := range t.values {
+		if !v.optional {
+			return false
+		}
+	}
+
+	return true
+}
+
 // result takes the result that matches this struct type and adapts it
 // if necessary (if the struct type is lifted or so on).
 func (t *ValueSet) result(r Result) Result {
@@ -436,6 +571,12 @@ func (t *ValueSet) result(r Result) Result {
 	// any pointers. We know this to be true already since we analyzed the
 	// function earlier.
 	if !t.lifted() {
+		// Copy the outputs since results may be shared (see FuncOnce) and
+		// we modify the outputs below.
+		out := make([]reflect.Value, len(r.out))
+		copy(out, r.out)
+		r.out = out
+
 		for i := uint8(0); i < t.structPointers; i++ {
 			r.out[0] = r.out[0].Elem()
 		}
@@ -526,15 +667,68 @@ func (v *Value) String() string {
 	}
 }
 
-func (v *Value) valueOrZero() reflect.Value {
-	if !v.Value.IsValid() {
+// fieldType returns the type of the struct field for this value. This
+// is the Optional or Lazy type for values of those types, otherwise Type.
+func (v *Value) fieldType() reflect.Type {
+	if v.optionalType != nil {
+		return v.optionalType
+	}
+	if v.lazyType != nil {
+		return v.lazyType
+	}
+
+	return v.Type
+}
+
+// fieldValue returns the value to set on the struct field for this value
+// given the value val. If val isn't valid, the zero value is used. For
+// lazy values, val may also be the Lazy value itself.
+func (v *Value) fieldValue(val reflect.Value) reflect.Value {
+	if v.optionalType != nil {
+		return newOptional(v.optionalType, val)
+	}
+	if v.lazyType != nil {
+		if val.IsValid() && val.Type() == v.lazyType {
+			return val
+		}
+		if !val.IsValid() {
+			val = reflect.Zero(v.Type)
+		}
+
+		return newLazy(v.lazyType, func() (reflect.Value, error) {
+			return val, nil
+		})
+	}
+
+	if !val.IsValid() {
 		return reflect.Zero(v.Type)
 	}
 
-	return v.Value
+	return val
+}
+
+// fromField returns the value for this value from the value of its
+// struct field. This is the inverse of fieldValue. Lazy values that
+// haven't been resolved yet have no value.
+func (v *Value) fromField(field reflect.Value) reflect.Value {
+	if v.optionalType != nil {
+		return optionalValue(field)
+	}
+	if v.lazyType != nil {
+		return lazyFieldValue(field)
+	}
+
+	return field
 }
 
 func (v *Value) vertex() graph.Vertex {
+	if v.collected() {
+		return &collectVertex{
+			Type:  v.Type,
+			Named: v.namedMap,
+		}
+	}
+
 	switch v.Kind() {
 	case ValueNamed:
 		return &valueVertex{